@@ -1,8 +1,12 @@
 package qi
 
 import (
+	"errors"
 	"reflect"
 	"runtime"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 // BoundHandler 携带类型元信息的包装 handler。
@@ -19,10 +23,11 @@ type BoundHandler struct {
 func Bind[Req any, Resp any](fn func(*Context, *Req) (*Resp, error)) BoundHandler {
 	reqType := reflect.TypeFor[Req]()
 	respType := reflect.TypeFor[Resp]()
+	tags := newBindTags(reqType)
 
 	handler := func(c *Context) {
 		req := new(Req)
-		if !bindRequest(c, req, reqType) {
+		if !bindRequest(c, req, tags) {
 			return
 		}
 		resp, err := fn(c, req)
@@ -67,10 +72,11 @@ func BindR[Resp any](fn func(*Context) (*Resp, error)) BoundHandler {
 // 适用于无需返回响应体的场景（如 DELETE），成功时自动返回 c.OK(nil)。
 func BindE[Req any](fn func(*Context, *Req) error) BoundHandler {
 	reqType := reflect.TypeFor[Req]()
+	tags := newBindTags(reqType)
 
 	handler := func(c *Context) {
 		req := new(Req)
-		if !bindRequest(c, req, reqType) {
+		if !bindRequest(c, req, tags) {
 			return
 		}
 		if err := fn(c, req); err != nil {
@@ -107,8 +113,59 @@ func BindRE(fn func(*Context) error) BoundHandler {
 	}
 }
 
+// bindTags 请求结构体中各绑定来源的 tag，Bind/BindE 注册时计算一次，由 handler 闭包捕获。
+type bindTags struct {
+	form, uri, header, cookie bool
+	headerNames               []string // header tag 名，按此读取请求头
+	sourceFields              [][]int  // 带 uri、header、cookie tag 的字段索引，绑定请求体后置零
+}
+
+func newBindTags(t reflect.Type) bindTags {
+	return bindTags{
+		form:         typeHasTag(t, "form"),
+		uri:          typeHasTag(t, "uri"),
+		header:       typeHasTag(t, "header"),
+		cookie:       typeHasTag(t, "cookie"),
+		headerNames:  typeTagNames(t, "header"),
+		sourceFields: sourceFieldIndexes(t, nil),
+	}
+}
+
+// sourceFieldIndexes 返回带 uri、header、cookie tag 的字段（含嵌入）索引。
+func sourceFieldIndexes(t reflect.Type, prefix []int) [][]int {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var out [][]int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), prefix...), i)
+		switch {
+		case field.Tag.Get("uri") != "" || field.Tag.Get("header") != "" || field.Tag.Get("cookie") != "":
+			if field.IsExported() {
+				out = append(out, index)
+			}
+		case field.Anonymous:
+			out = append(out, sourceFieldIndexes(field.Type, index)...)
+		}
+	}
+	return out
+}
+
+// resetSourceFields 将路径参数、请求头、Cookie 字段置零，避免请求体中的同名字段伪造这些来源的值。
+func resetSourceFields(req any, indexes [][]int) {
+	v := reflect.ValueOf(req).Elem()
+	for _, index := range indexes {
+		if f, err := v.FieldByIndexErr(index); err == nil {
+			f.SetZero()
+		}
+	}
+}
+
 // typeHasTag 扫描结构体字段（含嵌入）检查是否有指定 tag。
-// 注册时调用一次，闭包捕获结果。
 func typeHasTag(t reflect.Type, tagName string) bool {
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -131,30 +188,108 @@ func typeHasTag(t reflect.Type, tagName string) bool {
 	return false
 }
 
-// bindRequest 统一请求绑定逻辑。
-// 根据 HTTP 方法和结构体 tag 自动选择 Bind/BindQuery/BindURI。
-// 返回 false 表示绑定失败（已自动写入错误响应）。
-func bindRequest(c *Context, req any, reqType reflect.Type) bool {
-	hasFormTag := typeHasTag(reqType, "form")
-	hasURITag := typeHasTag(reqType, "uri")
+// typeTagNames 返回结构体字段（含嵌入）指定 tag 的名称。
+func typeTagNames(t reflect.Type, tagName string) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := fieldTagName(field, []string{tagName}); name != "" {
+			names = append(names, name)
+		}
+		if field.Anonymous {
+			names = append(names, typeTagNames(field.Type, tagName)...)
+		}
+	}
+	return names
+}
 
-	if isBodyMethod(c.Request().Method) {
-		if err := c.Bind(req); err != nil {
+// mapPath 按 uri tag 将路径参数映射到结构体，不做校验。
+func mapPath(c *Context, req any) error {
+	values := make(map[string][]string, len(c.ctx.Params))
+	for _, p := range c.ctx.Params {
+		values[p.Key] = []string{p.Value}
+	}
+	return binding.MapFormWithTag(req, values, "uri")
+}
+
+// mapQuery 按 form tag 将查询参数映射到结构体，不做校验。
+func mapQuery(c *Context, req any) error {
+	return binding.MapFormWithTag(req, c.Request().URL.Query(), "form")
+}
+
+// mapHeaders 按 header tag 将请求头映射到结构体，不做校验。
+func mapHeaders(c *Context, req any, names []string) error {
+	values := make(map[string][]string)
+	for _, name := range names {
+		if v := c.Request().Header.Values(name); len(v) > 0 {
+			values[name] = v
+		}
+	}
+	return binding.MapFormWithTag(req, values, "header")
+}
+
+// mapCookies 按 cookie tag 将 Cookie 映射到结构体，不做校验。
+func mapCookies(c *Context, req any) error {
+	values := make(map[string][]string)
+	for _, cookie := range c.Request().Cookies() {
+		values[cookie.Name] = append(values[cookie.Name], cookie.Value)
+	}
+	return binding.MapFormWithTag(req, values, "cookie")
+}
+
+// bindRequest 统一请求绑定逻辑，与 HTTP 方法无关地按 tag 绑定所有来源：
+// uri → 路径参数，form → 查询参数，header → 请求头，cookie → Cookie，
+// POST/PUT/PATCH 的请求体按 Content-Type 绑定（与查询参数同名的字段请求体优先）。
+// 路径参数、请求头、Cookie 字段只从对应来源取值，请求体中的同名字段不会覆盖它们。
+// 各来源先映射不校验，全部完成后统一校验一次，避免某一来源的必填字段在绑定其他来源时校验失败。
+// 返回 false 表示绑定失败（已自动写入错误响应）。
+func bindRequest(c *Context, req any, tags bindTags) bool {
+	if tags.form {
+		if err := c.bindOrFail(req, "query", mapQuery(c, req)); err != nil {
 			return false
 		}
-	} else if hasFormTag {
-		// 仅当结构体含有 form tag 时才调用 BindQuery，
-		// 否则会验证仅有 uri tag 的字段导致必填校验失败
-		if err := c.BindQuery(req); err != nil {
+	}
+
+	body := isBodyMethod(c.Request().Method)
+	if body {
+		// 请求体绑定的校验错误忽略，所有来源映射完成后统一校验
+		var verrs validator.ValidationErrors
+		if err := c.ctx.ShouldBind(req); err != nil && !errors.As(err, &verrs) {
+			c.bindOrFail(req, "body", err)
 			return false
 		}
+		resetSourceFields(req, tags.sourceFields)
 	}
 
-	if hasURITag {
-		if err := c.BindURI(req); err != nil {
+	// 仅当结构体含有对应 tag 时才映射，form 映射会按字段名匹配无 tag 的字段
+	sources := []struct {
+		ok bool
+		in string
+		fn func() error
+	}{
+		{tags.uri, "path", func() error { return mapPath(c, req) }},
+		{tags.header, "header", func() error { return mapHeaders(c, req, tags.headerNames) }},
+		{tags.cookie, "cookie", func() error { return mapCookies(c, req) }},
+	}
+	for _, src := range sources {
+		if !src.ok {
+			continue
+		}
+		if err := c.bindOrFail(req, src.in, src.fn()); err != nil {
 			return false
 		}
 	}
 
+	if body || tags.form || tags.uri || tags.header || tags.cookie {
+		if err := c.bindOrFail(req, c.bodyLocation(), binding.Validator.ValidateStruct(req)); err != nil {
+			return false
+		}
+	}
 	return true
 }
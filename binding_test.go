@@ -1,6 +1,9 @@
 package qi
 
 import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -299,11 +302,13 @@ func TestBind_MixedWithRequiredBinding(t *testing.T) {
 	req := httptest.NewRequest("GET", "/users/5/items?page=3", nil)
 	r.ServeHTTP(w, req)
 
-	t.Logf("Status: %d, Body: %s", w.Code, w.Body.String())
-	// 因为结构体有 form tag → 触发 BindQuery → 校验所有 binding tag → ID 为空 → 失败
-	if w.Code == http.StatusOK {
-		m, _ := parseResponse(w.Body.Bytes())
-		t.Errorf("expected BindQuery validation failure, got 200, data=%v", m["data"])
+	// 路径参数和查询参数都映射完成后才统一校验 → ID 已绑定 → 成功
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+	m, _ := parseResponse(w.Body.Bytes())
+	if m["data"] != "5" {
+		t.Errorf("data = %v, want 5", m["data"])
 	}
 }
 
@@ -366,3 +371,250 @@ func TestBind_PureURIWithRequired(t *testing.T) {
 		t.Errorf("data = %v, want 42", m["data"])
 	}
 }
+
+func TestBind_MultipartUpload(t *testing.T) {
+	r, w := setupRouter()
+	var got string
+	upload := BindE(func(c *Context, req *uploadAvatarReq) error {
+		got = req.Name + ":" + req.Avatar.Filename
+		return nil
+	})
+	r.POST("/avatar", toGinHandler(upload.Handler))
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("name", "alice")
+	fw, _ := mw.CreateFormFile("avatar", "a.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "/avatar", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got != "alice:a.png" {
+		t.Errorf("bound = %q, want alice:a.png", got)
+	}
+}
+
+type nestedItem struct {
+	SKU string `json:"sku" binding:"required"`
+	Qty int    `json:"qty" binding:"gte=1"`
+}
+
+type orderReq struct {
+	ShopID string       `uri:"shop_id" binding:"omitempty,len=4"`
+	Name   string       `json:"name" binding:"required,min=2"`
+	Status string       `json:"status" binding:"omitempty,oneof=draft paid"`
+	Items  []nestedItem `json:"items" binding:"required,dive"`
+}
+
+func TestBind_StructuredFieldErrors(t *testing.T) {
+	r, w := setupRouter()
+	r.POST("/orders", toGinHandler(Bind(func(c *Context, req *orderReq) (*userResp, error) {
+		return &userResp{}, nil
+	}).Handler))
+
+	body := `{"name":"a","status":"void","items":[{"sku":"x","qty":1},{"qty":0}]}`
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Errors []FieldError `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if resp.Code != ErrBadRequest.Code || resp.Message != ErrBadRequest.Message {
+		t.Errorf("code/message = %d/%q", resp.Code, resp.Message)
+	}
+	want := map[string]FieldError{
+		"name":         {Rule: "min", Param: "2", Message: "length must be >= 2"},
+		"status":       {Rule: "oneof", Param: "draft paid", Message: "must be one of [draft, paid]"},
+		"items[1].sku": {Rule: "required", Message: "is required"},
+		"items[1].qty": {Rule: "gte", Param: "1", Message: "must be >= 1"},
+	}
+	if len(resp.Data.Errors) != len(want) {
+		t.Fatalf("errors = %+v", resp.Data.Errors)
+	}
+	for _, fe := range resp.Data.Errors {
+		w, ok := want[fe.Field]
+		if !ok || fe.In != "body" || fe.Rule != w.Rule || fe.Param != w.Param || fe.Message != w.Message {
+			t.Errorf("unexpected field error %+v", fe)
+		}
+	}
+}
+
+func TestBind_TypeMismatchFieldError(t *testing.T) {
+	r, w := setupRouter()
+	r.POST("/orders", toGinHandler(Bind(func(c *Context, req *orderReq) (*userResp, error) {
+		return &userResp{}, nil
+	}).Handler))
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"name":123}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	m, _ := parseResponse(w.Body.Bytes())
+	data, _ := m["data"].(map[string]any)
+	list, _ := data["errors"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["field"] != "name" || list[0].(map[string]any)["rule"] != "type" {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+type tenantReq struct {
+	Tenant  string `header:"X-Tenant-Id" binding:"required"`
+	Session string `cookie:"session"`
+	Page    int    `form:"page"`
+	Name    string `json:"name"`
+}
+
+func TestBind_HeaderAndCookie(t *testing.T) {
+	r, _ := setupRouter()
+	var got tenantReq
+	handler := toGinHandler(Bind(func(c *Context, req *tenantReq) (*userResp, error) {
+		got = *req
+		return &userResp{}, nil
+	}).Handler)
+	r.GET("/tenants", handler)
+	r.POST("/tenants", handler)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/tenants?page=2", nil)
+	req.Header.Set("X-Tenant-Id", "t1")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || got.Tenant != "t1" || got.Session != "s1" || got.Page != 2 {
+		t.Fatalf("GET status = %d, req = %+v, body = %s", w.Code, got, w.Body.String())
+	}
+
+	got = tenantReq{}
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/tenants", strings.NewReader(`{"name":"acme"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-Id", "t2")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || got.Tenant != "t2" || got.Name != "acme" {
+		t.Fatalf("POST status = %d, req = %+v, body = %s", w.Code, got, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/tenants", nil))
+	m, _ := parseResponse(w.Body.Bytes())
+	data, _ := m["data"].(map[string]any)
+	list, _ := data["errors"].([]any)
+	if w.Code != http.StatusBadRequest || len(list) != 1 {
+		t.Fatalf("missing header: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if fe := list[0].(map[string]any); fe["field"] != "X-Tenant-Id" || fe["in"] != "header" || fe["rule"] != "required" {
+		t.Errorf("field error = %v", fe)
+	}
+}
+
+type updateOrderReq struct {
+	ID     string `uri:"id" binding:"required"`
+	DryRun bool   `form:"dry_run"`
+	Limit  int    `form:"limit" binding:"omitempty,max=10"`
+	Name   string `json:"name" binding:"required"`
+}
+
+func TestBind_BodyWithQueryParams(t *testing.T) {
+	r, _ := setupRouter()
+	var got updateOrderReq
+	r.PUT("/orders/:id", toGinHandler(Bind(func(c *Context, req *updateOrderReq) (*userResp, error) {
+		got = *req
+		return &userResp{}, nil
+	}).Handler))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/orders/7?dry_run=true", strings.NewReader(`{"name":"acme","id":"8"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || got.ID != "7" || !got.DryRun || got.Name != "acme" {
+		t.Fatalf("status = %d, req = %+v, body = %s", w.Code, got, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/orders/7?limit=20", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	m, _ := parseResponse(w.Body.Bytes())
+	data, _ := m["data"].(map[string]any)
+	list, _ := data["errors"].([]any)
+	want := map[string]string{"limit": "query", "name": "body"}
+	if w.Code != http.StatusBadRequest || len(list) != len(want) {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	for _, item := range list {
+		fe := item.(map[string]any)
+		if want[fe["field"].(string)] != fe["in"] {
+			t.Errorf("field error = %v", fe)
+		}
+	}
+}
+
+func TestBind_BodyCannotOverrideSources(t *testing.T) {
+	r, _ := setupRouter()
+	var got tenantReq
+	r.POST("/tenants", toGinHandler(Bind(func(c *Context, req *tenantReq) (*userResp, error) {
+		got = *req
+		return &userResp{}, nil
+	}).Handler))
+
+	// 请求体中与字段名同名的 key 不会覆盖请求头和 Cookie 的值
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/tenants", strings.NewReader(`{"name":"acme","Tenant":"evil","Session":"evil"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-Id", "t1")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || got.Tenant != "t1" || got.Session != "" || got.Name != "acme" {
+		t.Fatalf("status = %d, req = %+v, body = %s", w.Code, got, w.Body.String())
+	}
+
+	// 缺少请求头时不能通过请求体补上
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/tenants", strings.NewReader(`{"name":"acme","Tenant":"evil"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"X-Tenant-Id"`) {
+		t.Fatalf("missing header: status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestBind_PathParamsValidated(t *testing.T) {
+	type renameReq struct {
+		ID   string `uri:"id" binding:"numeric"`
+		Name string `json:"name" binding:"required"`
+	}
+	r, _ := setupRouter()
+	r.PUT("/orders/:id", toGinHandler(Bind(func(c *Context, req *renameReq) (*userResp, error) {
+		return &userResp{}, nil
+	}).Handler))
+
+	// 路径参数与请求体映射完成后统一校验，请求体中的合法值不能掩盖非法的路径参数
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/orders/abc", strings.NewReader(`{"name":"acme","ID":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	m, _ := parseResponse(w.Body.Bytes())
+	data, _ := m["data"].(map[string]any)
+	list, _ := data["errors"].([]any)
+	if w.Code != http.StatusBadRequest || len(list) != 1 {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if fe := list[0].(map[string]any); fe["field"] != "id" || fe["in"] != "path" || fe["rule"] != "numeric" {
+		t.Errorf("field error = %v", fe)
+	}
+}
@@ -0,0 +1,236 @@
+// qi-openapi 离线导出 qi 应用的 OpenAPI 文档，不需要监听端口，用于 CI 生成、比对和提交 spec。
+//
+// 工具在目标模块内生成一个临时 main 包，导入用户的路由注册函数并调用 Engine.ExportOpenAPI。
+// 注册函数支持以下签名：
+//
+//	func(*qi.Engine)         // 由工具创建启用 OpenAPI 的 Engine
+//	func() *qi.Engine        // 由用户创建 Engine（需启用 WithOpenAPI）
+//	func() (*qi.Engine, error)
+//
+// 用法：
+//
+//	qi-openapi -pkg github.com/me/app/router -func Register -o api/openapi.yaml
+//	qi-openapi -pkg github.com/me/app/router -o api/openapi.json -check // spec 过期时退出码为 1
+//	qi-openapi -pkg github.com/me/app/router -o api/openapi.json -base old.json // 输出变更报告，含 breaking 变更时退出码为 1
+//	qi-openapi -pkg github.com/me/app/router -client sdk/client.go,web/src/api.ts // 同时生成 Go / TypeScript 客户端
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/tokmz/qi/internal/openapi"
+	"github.com/tokmz/qi/internal/openapi/diff"
+)
+
+type options struct {
+	Pkg            string
+	Func           string
+	Output         string
+	Format         string
+	Dir            string
+	Check          bool
+	Base           string
+	Title          string
+	Version        string
+	OpenAPIVersion string
+	Clients        string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.Pkg, "pkg", "", "注册函数所在包的导入路径（必填）")
+	flag.StringVar(&opts.Func, "func", "Register", "注册函数名")
+	flag.StringVar(&opts.Output, "o", "openapi.json", "输出文件，- 表示标准输出")
+	flag.StringVar(&opts.Format, "format", "", "输出格式 json/yaml，默认按扩展名推断")
+	flag.StringVar(&opts.Dir, "dir", ".", "目标模块目录")
+	flag.BoolVar(&opts.Check, "check", false, "仅比对：输出文件与生成结果不一致时退出码为 1")
+	flag.StringVar(&opts.Base, "base", "", "基准 spec 文件，与生成结果比较并以 JSON 输出变更报告")
+	flag.StringVar(&opts.Title, "title", "OpenAPI", "文档标题（仅 func(*qi.Engine) 签名生效）")
+	flag.StringVar(&opts.Version, "version", "", "API 版本（仅 func(*qi.Engine) 签名生效）")
+	flag.StringVar(&opts.OpenAPIVersion, "openapi-version", "", "OpenAPI 版本，如 3.1.0（仅 func(*qi.Engine) 签名生效）")
+	flag.StringVar(&opts.Clients, "client", "", "生成客户端，逗号分隔的 .go/.ts 文件路径")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "qi-openapi: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.Pkg == "" || opts.Func == "" {
+		return fmt.Errorf("-pkg and -func are required")
+	}
+	if (opts.Check || opts.Base != "") && opts.Output == "-" {
+		return fmt.Errorf("-check and -base require an output file")
+	}
+
+	output := opts.Output
+	if output != "-" {
+		abs, err := filepath.Abs(output)
+		if err != nil {
+			return err
+		}
+		output = abs
+	}
+
+	// 临时包必须位于目标模块内，才能按模块依赖解析用户包
+	tmp, err := os.MkdirTemp(opts.Dir, ".qi-openapi-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	src, err := renderMain(opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), src, 0o644); err != nil {
+		return err
+	}
+
+	target := output
+	if opts.Check {
+		target = filepath.Join(tmp, "openapi"+filepath.Ext(output))
+	}
+
+	args := []string{"run", "./" + filepath.Base(tmp), target, opts.Format}
+	for _, client := range strings.Split(opts.Clients, ",") {
+		if client = strings.TrimSpace(client); client == "" {
+			continue
+		}
+		abs, err := filepath.Abs(client)
+		if err != nil {
+			return err
+		}
+		args = append(args, abs)
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = opts.Dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	if opts.Base != "" {
+		if err := compareBase(opts.Base, target); err != nil {
+			return err
+		}
+	}
+	if !opts.Check {
+		return nil
+	}
+	want, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	got, err := os.ReadFile(output)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%s is out of date, run qi-openapi without -check to regenerate", opts.Output)
+	}
+	return nil
+}
+
+// compareBase 比较基准 spec 与生成结果，报告写到标准输出。
+func compareBase(basePath, headPath string) error {
+	base, err := readDocument(basePath)
+	if err != nil {
+		return err
+	}
+	head, err := readDocument(headPath)
+	if err != nil {
+		return err
+	}
+	report := diff.Compare(base, head)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	if report.HasBreaking() {
+		return fmt.Errorf("%d breaking change(s) against %s", report.Breaking, basePath)
+	}
+	return nil
+}
+
+func readDocument(path string) (*openapi.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := openapi.ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// renderMain 生成调用注册函数并导出 spec 的临时 main 包源码。
+func renderMain(opts options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := mainTemplate.Execute(&buf, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by qi-openapi. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tokmz/qi"
+	target {{printf "%q" .Pkg}}
+)
+
+func main() {
+	var (
+		e   *qi.Engine
+		err error
+	)
+	switch fn := any(target.{{.Func}}).(type) {
+	case func(*qi.Engine):
+		e = qi.New(qi.WithMode("release"), qi.WithOpenAPI(&qi.OpenAPIConfig{
+			Title:          {{printf "%q" .Title}},
+			Version:        {{printf "%q" .Version}},
+			OpenAPIVersion: {{printf "%q" .OpenAPIVersion}},
+		}))
+		fn(e)
+	case func() *qi.Engine:
+		e = fn()
+	case func() (*qi.Engine, error):
+		e, err = fn()
+	default:
+		err = fmt.Errorf("%T is not a supported register function", fn)
+	}
+	if err == nil {
+		err = e.ExportOpenAPI(os.Args[1], os.Args[2])
+	}
+	for _, path := range os.Args[3:] {
+		if err != nil {
+			break
+		}
+		err = e.ExportClient(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qi-openapi: %v\n", err)
+		os.Exit(1)
+	}
+}
+`))
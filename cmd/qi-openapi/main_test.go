@@ -0,0 +1,34 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestRenderMain(t *testing.T) {
+	src, err := renderMain(options{
+		Pkg:            "example.com/app/router",
+		Func:           "Register",
+		Title:          `My "API"`,
+		OpenAPIVersion: "3.1.0",
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+		t.Fatalf("generated source is invalid: %v\n%s", err, src)
+	}
+	for _, want := range []string{`target "example.com/app/router"`, "target.Register", `"My \"API\""`} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source should contain %s:\n%s", want, src)
+		}
+	}
+}
+
+func TestRunRequiresPkg(t *testing.T) {
+	if err := run(options{Func: "Register"}); err == nil {
+		t.Fatal("expected error without -pkg")
+	}
+}
@@ -1,10 +1,12 @@
 package qi
 
 import (
+	"bytes"
 	"context"
 	"io/fs"
 	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -237,36 +239,49 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string, perm
 // Bind 自动绑定请求体到结构体（根据 Content-Type）
 // 示例：c.Bind(&user)
 func (c *Context) Bind(obj any) error {
-	return c.bindOrFail(c.ctx.ShouldBind(obj))
+	return c.bindOrFail(obj, c.bodyLocation(), c.ctx.ShouldBind(obj))
 }
 
 // BindJSON 绑定 JSON 请求体到结构体
 // 示例：c.BindJSON(&user)
 func (c *Context) BindJSON(obj any) error {
-	return c.bindOrFail(c.ctx.ShouldBindJSON(obj))
+	return c.bindOrFail(obj, "body", c.ctx.ShouldBindJSON(obj))
 }
 
 // BindQuery 绑定查询参数到结构体
 // 示例：c.BindQuery(&user)
 func (c *Context) BindQuery(obj any) error {
-	return c.bindOrFail(c.ctx.ShouldBindQuery(obj))
+	return c.bindOrFail(obj, "query", c.ctx.ShouldBindQuery(obj))
 }
 
 // BindURI 绑定 URI 参数到结构体
 // 示例：c.BindURI(&user)
 func (c *Context) BindURI(obj any) error {
-	return c.bindOrFail(c.ctx.ShouldBindUri(obj))
+	return c.bindOrFail(obj, "path", c.ctx.ShouldBindUri(obj))
 }
 
-// bindOrFail 绑定失败时自动写入错误响应
-func (c *Context) bindOrFail(err error) error {
+// bindOrFail 绑定失败时自动写入错误响应。
+// 校验和类型错误转换为字段级错误输出到 data.errors，in 为绑定来源（body/query/path）。
+func (c *Context) bindOrFail(obj any, in string, err error) error {
 	if err != nil {
-		c.Fail(ErrBadRequest.WithErr(err))
+		if fields, ok := c.bindFieldErrors(obj, in, err); ok {
+			c.Fail(ErrBadRequest.WithErr(fields))
+		} else {
+			c.Fail(ErrBadRequest.WithErr(err))
+		}
 		return err
 	}
 	return nil
 }
 
+// bodyLocation 返回 Bind 的绑定来源：GET 等无请求体的方法绑定查询参数。
+func (c *Context) bodyLocation() string {
+	if isBodyMethod(c.ctx.Request.Method) {
+		return "body"
+	}
+	return "query"
+}
+
 // ===== 请求信息获取 =====
 
 // ClientIP 获取客户端 IP
@@ -311,13 +326,32 @@ func (c *Context) traceID() string {
 	return ""
 }
 
-// respond 统一响应，自动填充 trace_id
-func (c *Context) respond(status int, code int, msg string, data any) {
-	resp := NewResponse(code, msg, data)
-	if tid := c.traceID(); tid != "" {
-		resp.TraceID = tid
+// respond 统一响应，自动填充 trace_id，由 ResponseRenderer 渲染响应体
+func (c *Context) respond(res Result) {
+	// 响应已开始输出（如 SSE、Stream）时不再追加统一响应
+	if c.ctx.Writer.Written() {
+		return
+	}
+	res.TraceID = c.traceID()
+	body := c.renderer().Render(c, res)
+	if body == nil {
+		c.ctx.Status(res.Status)
+		return
+	}
+	if mt, ok := body.(mediaTyper); ok {
+		c.ctx.Header("Content-Type", mt.MediaType())
+		c.ctx.JSON(res.Status, body)
+		return
+	}
+	// 按 Accept 协商编码器，编码失败时回退为 JSON
+	if enc := c.negotiate(); enc != nil {
+		var buf bytes.Buffer
+		if err := enc.Encode(&buf, body); err == nil {
+			c.ctx.Data(res.Status, enc.MediaType(), buf.Bytes())
+			return
+		}
 	}
-	c.ctx.JSON(status, resp)
+	c.ctx.JSON(res.Status, body)
 }
 
 // Header 设置响应头
@@ -330,14 +364,14 @@ func (c *Context) JSON(status int, data any) {
 	c.ctx.JSON(status, data)
 }
 
-// OK 成功响应，code=0
+// OK 成功响应，业务码为渲染器的成功码（默认 0）
 // 示例：c.OK(user) 或 c.OK(user, "创建成功")
 func (c *Context) OK(data any, msg ...string) {
-	m := "success"
+	res := Result{Status: http.StatusOK, Data: data}
 	if len(msg) > 0 {
-		m = msg[0]
+		res.Message = msg[0]
 	}
-	c.respond(http.StatusOK, 0, m, data)
+	c.respond(res)
 }
 
 // Fail 错误响应，自动从 *errors.Error 提取 code/status/message
@@ -347,19 +381,33 @@ func (c *Context) Fail(err error) {
 		c.OK(nil)
 		return
 	}
+	c.respond(c.failResult(err))
+}
+
+// failResult 将错误转换为响应内容，非 *errors.Error 视为 ErrServer
+func (c *Context) failResult(err error) Result {
 	code := errors.GetCode(err)
 	if code == -1 {
-		c.respond(http.StatusInternalServerError, ErrServer.Code, ErrServer.Message, nil)
-		return
+		return Result{Status: http.StatusInternalServerError, Code: ErrServer.Code, Message: c.localize(ErrServer.Code, ErrServer.Message), Err: err}
 	}
 	status := errors.GetStatus(err)
-	c.respond(status, code, err.Error(), nil)
+	e, _ := errors.As(err)
+	// 字段级校验错误：message 使用业务错误消息，字段明细放入 data.errors
+	if fields, ok := fieldErrors(err); ok {
+		return Result{Status: status, Code: code, Message: c.localize(code, e.Message), Data: map[string]any{"errors": fields}, Err: err}
+	}
+	// 启用国际化时替换默认消息，保留附加的原始错误信息
+	msg := err.Error()
+	if local := c.localize(code, e.Message); local != e.Message && strings.HasPrefix(msg, e.Message) {
+		msg = local + msg[len(e.Message):]
+	}
+	return Result{Status: status, Code: code, Message: msg, Err: err}
 }
 
 // FailWithCode 自定义 code 的错误响应
 // 示例：c.FailWithCode(10001, http.StatusBadRequest, "参数错误")
 func (c *Context) FailWithCode(code, status int, msg string) {
-	c.respond(status, code, msg, nil)
+	c.respond(Result{Status: status, Code: code, Message: msg, Err: errors.NewWithStatus(code, status, msg)})
 }
 
 // Page 分页响应
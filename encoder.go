@@ -0,0 +1,186 @@
+package qi
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// gin.Context 中存放响应编码器的键。
+const encodersKey = "qi.encoders"
+
+// MIMEJSON 统一响应的默认媒体类型，总是可用。
+const MIMEJSON = "application/json"
+
+// errUnsupportedValue 编码器无法编码该响应值，回退为 JSON。
+var errUnsupportedValue = errors.New("qi: encoder does not support value")
+
+// Encoder 响应编码器。通过 WithEncoders 注册后，OK / Fail / Page 等统一响应按 Accept 请求头选择编码器，
+// 编码失败或没有匹配的编码器时使用 JSON。
+type Encoder interface {
+	MediaType() string               // 媒体类型，如 "application/msgpack"
+	Encode(w io.Writer, v any) error // 编码统一响应（*Response）
+}
+
+// WithEncoders 注册 JSON 以外的响应编码器，OpenAPI 文档中的响应同时列出这些媒体类型。
+//
+// 示例：
+//
+//	qi.New(qi.WithEncoders(qi.XMLEncoder(), qi.MsgPackEncoder(), qi.ProtobufEncoder()))
+func WithEncoders(encoders ...Encoder) Option {
+	return func(c *Config) {
+		c.encoders = append(c.encoders, encoders...)
+	}
+}
+
+// XMLEncoder 返回 application/xml 编码器，data 无法编码为 XML（如 map）时回退为 JSON。
+func XMLEncoder() Encoder { return xmlEncoder{} }
+
+// MsgPackEncoder 返回 application/msgpack 编码器，字段名与 JSON 一致。
+func MsgPackEncoder() Encoder { return msgpackEncoder{} }
+
+// ProtobufEncoder 返回 application/x-protobuf 编码器。
+// 仅当 data 为 proto.Message 时生效，直接输出 data 的二进制编码（不含统一响应包装）；
+// 错误响应等其他情况回退为 JSON。
+func ProtobufEncoder() Encoder { return protobufEncoder{} }
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) MediaType() string { return "application/xml" }
+
+func (xmlEncoder) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+type msgpackEncoder struct{}
+
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+func (msgpackEncoder) MediaType() string { return "application/msgpack" }
+
+func (msgpackEncoder) Encode(w io.Writer, v any) error {
+	return codec.NewEncoder(w, msgpackHandle).Encode(v)
+}
+
+type protobufEncoder struct{}
+
+func (protobufEncoder) MediaType() string { return "application/x-protobuf" }
+
+func (protobufEncoder) Encode(w io.Writer, v any) error {
+	if resp, ok := v.(*Response); ok {
+		v = resp.Data
+	}
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return errUnsupportedValue
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// encodersMiddleware 将已注册的编码器写入 gin.Context，供统一响应协商使用。
+func encodersMiddleware(encoders []Encoder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(encodersKey, encoders)
+		c.Next()
+	}
+}
+
+// mediaTypes 返回统一响应支持的媒体类型，JSON 在首位。
+func mediaTypes(encoders []Encoder) []string {
+	out := []string{MIMEJSON}
+	for _, enc := range encoders {
+		if mt := enc.MediaType(); mt != MIMEJSON && !containsString(out, mt) {
+			out = append(out, mt)
+		}
+	}
+	return out
+}
+
+// negotiate 按 Accept 请求头选择编码器，JSON 或无匹配时返回 nil。
+// 注册了编码器时响应体随 Accept 变化，设置 Vary: Accept 避免缓存返回其他编码的响应。
+func (c *Context) negotiate() Encoder {
+	v, ok := c.ctx.Get(encodersKey)
+	if !ok {
+		return nil
+	}
+	encoders, _ := v.([]Encoder)
+	if h := c.ctx.Writer.Header(); !slices.Contains(h.Values("Vary"), "Accept") {
+		h.Add("Vary", "Accept")
+	}
+	for _, accept := range parseAccept(c.ctx.GetHeader("Accept")) {
+		if mediaTypeMatches(accept, MIMEJSON) {
+			return nil
+		}
+		for _, enc := range encoders {
+			if mediaTypeMatches(accept, enc.MediaType()) {
+				return enc
+			}
+		}
+	}
+	return nil
+}
+
+// parseAccept 解析 Accept 请求头，按权重从高到低返回媒体类型范围，忽略 q=0。
+func parseAccept(header string) []string {
+	type entry struct {
+		mediaType string
+		q         float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{mt, q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.mediaType
+	}
+	return out
+}
+
+// mediaTypeMatches 判断 Accept 中的媒体类型范围（支持 */* 和 type/*）是否包含 mediaType。
+func mediaTypeMatches(accept, mediaType string) bool {
+	if accept == "*/*" || accept == mediaType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(accept, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package qi
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestEncoders_Negotiate(t *testing.T) {
+	e := New(WithMode("test"), WithEncoders(XMLEncoder(), MsgPackEncoder(), ProtobufEncoder()))
+	e.GET("/user", func(c *Context) { c.OK(userResp{ID: 1, Name: "qi"}) })
+	e.GET("/page", func(c *Context) { c.Page(1, []userResp{{ID: 1}}) })
+	e.GET("/proto", func(c *Context) { c.OK(wrapperspb.String("qi")) })
+	e.GET("/fail", func(c *Context) { c.Fail(ErrNotFound) })
+
+	do := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		path, accept, want string
+	}{
+		{"/user", "", "application/json"},
+		{"/user", "*/*", "application/json"},
+		{"/user", "text/html", "application/json"},
+		{"/user", "application/xml", "application/xml"},
+		{"/user", "application/json;q=0.5, application/msgpack", "application/msgpack"},
+		{"/user", "application/*", "application/json"},
+		{"/page", "application/xml", "application/json"}, // map 无法编码为 XML，回退为 JSON
+		{"/proto", "application/x-protobuf", "application/x-protobuf"},
+		{"/user", "application/x-protobuf", "application/json"}, // 非 proto.Message 回退为 JSON
+		{"/fail", "application/msgpack", "application/msgpack"},
+	}
+	for _, tt := range tests {
+		w := do(tt.path, tt.accept)
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("GET %s Accept=%q: Content-Type = %q, want %q", tt.path, tt.accept, got, tt.want)
+		}
+		// 包括回退为 JSON 的响应，缓存都需要按 Accept 区分
+		if got := w.Header().Get("Vary"); got != "Accept" {
+			t.Errorf("GET %s Accept=%q: Vary = %q, want Accept", tt.path, tt.accept, got)
+		}
+	}
+
+	// 保留中间件设置的 Vary
+	e.GET("/cors", func(c *Context) {
+		c.Header("Vary", "Origin")
+		c.OK(userResp{ID: 1})
+	})
+	if got := do("/cors", "application/xml").Header().Values("Vary"); !slices.Equal(got, []string{"Origin", "Accept"}) {
+		t.Errorf("Vary with middleware = %q", got)
+	}
+
+	// 未注册编码器时响应不随 Accept 变化
+	plain := New(WithMode("test"))
+	plain.GET("/user", func(c *Context) { c.OK(userResp{ID: 1}) })
+	w := httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest("GET", "/user", nil))
+	if got := w.Header().Get("Vary"); got != "" {
+		t.Errorf("Vary without encoders = %q", got)
+	}
+
+	w = do("/user", "application/xml")
+	var x struct {
+		XMLName xml.Name `xml:"response"`
+		Code    int      `xml:"code"`
+		Data    struct {
+			Name string `xml:"Name"`
+		} `xml:"data"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &x); err != nil || x.Code != 0 || x.Data.Name != "qi" {
+		t.Errorf("xml body = %s, err = %v", w.Body.String(), err)
+	}
+
+	w = do("/fail", "application/msgpack")
+	var m Response
+	if err := codec.NewDecoderBytes(w.Body.Bytes(), &codec.MsgpackHandle{}).Decode(&m); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	if w.Code != http.StatusNotFound || m.Code != ErrNotFound.Code || m.Message != ErrNotFound.Message {
+		t.Errorf("msgpack status = %d, body = %+v", w.Code, m)
+	}
+
+	w = do("/proto", "application/x-protobuf")
+	var s wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &s); err != nil || s.GetValue() != "qi" {
+		t.Errorf("protobuf value = %q, err = %v", s.GetValue(), err)
+	}
+}
+
+func TestEncoders_OpenAPIMediaTypes(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}), WithEncoders(MsgPackEncoder()))
+	handler := func(c *Context) { c.OK(nil) }
+	e.API().GET("/users", handler).Response(userResp{}).Errors(ErrNotFound).Done()
+	e.API().GET("/proto", handler).Response(userResp{}).Produces("application/x-protobuf").Done()
+	e.API().GET("/raw", handler).Response(userResp{}).RawResponse().Done()
+
+	doc := buildTestDoc(t, e)
+
+	users := doc.Paths["/users"].Get
+	for _, status := range []string{"200", "404"} {
+		content := users.Responses[status].Content
+		if content["application/json"] == nil || content["application/msgpack"] == nil {
+			t.Errorf("/users %s content = %v, want json and msgpack", status, content)
+		}
+	}
+	if content := doc.Paths["/proto"].Get.Responses["200"].Content; len(content) != 1 || content["application/x-protobuf"] == nil {
+		t.Errorf("/proto content = %v, want protobuf only", content)
+	}
+	if content := doc.Paths["/raw"].Get.Responses["200"].Content; len(content) != 1 || content["application/json"] == nil {
+		t.Errorf("/raw content = %v, want json only", content)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -35,6 +36,10 @@ type Engine struct {
 	mode            string                      // 运行模式
 	tracingShutdown func(context.Context) error // 链路追踪关闭函数
 	routeMeta       map[string]RouteMeta        // 路由元信息注册表，key="METHOD /full/path"
+	hub             *Hub                        // WebSocket 连接中心
+	lifecycle       lifecycle                   // 组件注册表和生命周期钩子
+	workers         workerGroup                 // 后台任务监督器
+	health          *health                     // 健康检查（可选）
 }
 
 // Config 定义 Engine 的常用运行配置。
@@ -48,11 +53,19 @@ type Config struct {
 	MaxHeaderBytes    int           // 最大请求头字节数
 
 	ShutdownTimeout time.Duration // 关闭超时时间
+	ShutdownReserve time.Duration // ShutdownTimeout 中预留给 OnShutdown 钩子和组件关闭的时间，默认 2s，超过 ShutdownTimeout 的一半时取一半
+	StartTimeout    time.Duration // 单个 OnStart 钩子或组件启动的超时时间，默认 15s，0 表示不限制
+	StopTimeout     time.Duration // 单个 OnShutdown 钩子或组件关闭的默认超时时间，默认 1s，0 表示只受 ShutdownTimeout 限制
 	ShutdownSignals []os.Signal   // 关闭信号
 
-	openAPIConfig *OpenAPIConfig // OpenAPI 配置（未导出）
-	tracingConfig *TracingConfig // 链路追踪配置（未导出）
-	loggerConfig  *LoggerConfig  // 日志中间件配置（未导出）
+	openAPIConfig *OpenAPIConfig   // OpenAPI 配置（未导出）
+	tracingConfig *TracingConfig   // 链路追踪配置（未导出）
+	loggerConfig  *LoggerConfig    // 日志中间件配置（未导出）
+	i18nConfig    *I18nConfig      // 国际化配置（未导出）
+	encoders      []Encoder        // JSON 以外的响应编码器（未导出）
+	renderer      ResponseRenderer // 统一响应渲染器（未导出）
+	wsConfig      *WebSocketConfig // WebSocket 配置（未导出）
+	healthConfig  *HealthConfig    // 健康检查配置（未导出）
 }
 
 type Option func(*Config)
@@ -79,6 +92,9 @@ func defaultConfig() *Config {
 		MaxHeaderBytes:    1 << 20,
 
 		ShutdownTimeout: 5 * time.Second,
+		ShutdownReserve: 2 * time.Second,
+		StartTimeout:    15 * time.Second,
+		StopTimeout:     time.Second,
 		ShutdownSignals: []os.Signal{os.Interrupt, syscall.SIGTERM},
 	}
 }
@@ -98,13 +114,20 @@ func New(opts ...Option) *Engine {
 	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {}
 
 	engine := gin.New()
+	registerBuiltinValidations()
 
+	// 渲染器先于 panic 恢复注册，恢复响应同样使用自定义渲染器
+	if cfg.renderer != nil {
+		engine.Use(rendererMiddleware(cfg.renderer))
+	}
 	engine.Use(recoveryMiddleware())
 
 	e := &Engine{
 		engine:    engine,
 		router:    &routerStore{},
 		routeMeta: make(map[string]RouteMeta),
+		hub:       newHub(),
+		lifecycle: lifecycle{startTimeout: cfg.StartTimeout, stopTimeout: cfg.StopTimeout},
 		server: &http.Server{
 			Addr:              cfg.Addr,
 			Handler:           engine,
@@ -124,13 +147,39 @@ func New(opts ...Option) *Engine {
 			openapi.WithTitle(cfg.openAPIConfig.Title),
 			openapi.WithDescription(cfg.openAPIConfig.Description),
 			openapi.WithVersion(cfg.openAPIConfig.Version),
+			openapi.WithOpenAPIVersion(cfg.openAPIConfig.OpenAPIVersion),
 		}
 		if len(cfg.openAPIConfig.Servers) > 0 {
 			opts = append(opts, openapi.WithServers(cfg.openAPIConfig.Servers...))
 		}
+		for name, scheme := range cfg.openAPIConfig.SecuritySchemes {
+			opts = append(opts, openapi.WithSecurityScheme(name, scheme))
+		}
+		for _, u := range cfg.openAPIConfig.Unions {
+			opts = append(opts, openapi.WithUnion(u))
+		}
+		if env := e.envelope(); env != nil {
+			opts = append(opts, openapi.WithResponseEnvelope(env.Name, env.Type, env.DataField))
+		}
 		e.api = openapi.New(opts...)
 	}
 
+	// 健康检查端点先于全局中间件注册，探针请求不记录日志、不产生 span
+	if cfg.healthConfig != nil {
+		e.registerHealth()
+	}
+
+	// 注册国际化中间件
+	if cfg.i18nConfig != nil {
+		cfg.i18nConfig.normalize()
+		e.engine.Use(i18nMiddleware(cfg.i18nConfig))
+	}
+
+	// 注册响应编码器
+	if len(cfg.encoders) > 0 {
+		e.engine.Use(encodersMiddleware(cfg.encoders))
+	}
+
 	// 注册日志中间件
 	if cfg.loggerConfig != nil {
 		e.engine.Use(ilogging.Middleware(&ilogging.Config{
@@ -241,40 +290,103 @@ func (e *Engine) Any(path string, handlers ...HandlerFunc) {
 }
 
 // Run 启动 HTTP 服务并阻塞，直到收到关闭信号后优雅退出。
+// 监听之前按依赖顺序启动已注册的组件并执行 OnStart 钩子，监听成功后启动 Go 注册的后台任务；
+// 关闭时先取消后台任务的 ctx，依次关闭 WebSocket 连接、链路追踪、HTTP 服务并等待后台任务退出，
+// 再执行 OnShutdown 钩子并按相反顺序关闭组件，
+// 整个过程受 ShutdownTimeout 限制，其中 ShutdownReserve 预留给关闭钩子和组件，返回汇总的关闭错误。
 func (e *Engine) Run() error {
 	// 构建 OpenAPI spec 并注册端点（所有路由已注册完毕）
 	e.buildOpenAPISpec()
 
+	// 启动组件和 OnStart 钩子，失败时已启动的组件会被关闭
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		return err
+	}
+
 	// 打印 banner + 路由表 + 运行信息
 	e.printBanner()
 
+	addr := e.server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		ctx, cancel := e.shutdownContext()
+		defer cancel()
+		return errors.Join(err, e.lifecycle.stop(ctx))
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
-		if err := e.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := e.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 	}()
 
+	// 监听成功后启动后台任务
+	e.workers.start()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, e.cfg.ShutdownSignals...)
+	defer signal.Stop(quit)
 
 	select {
 	case err := <-errCh:
-		return err
+		return errors.Join(err, e.shutdown())
 	case <-quit:
 	}
+	// 就绪探针立即失败，等待负载均衡摘除流量后再关闭
+	e.drain(quit)
+	return e.shutdown()
+}
+
+// shutdownContext 返回受 ShutdownTimeout 限制的 context。
+func (e *Engine) shutdownContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.cfg.ShutdownTimeout)
+}
+
+// shutdownReserve 返回预留给关闭钩子和组件的时间，不超过 ShutdownTimeout 的一半。
+func (e *Engine) shutdownReserve() time.Duration {
+	return max(min(e.cfg.ShutdownReserve, e.cfg.ShutdownTimeout/2), 0)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.ShutdownTimeout)
+// shutdown 优雅关闭 HTTP 服务和已启动的组件。
+func (e *Engine) shutdown() error {
+	ctx, cancel := e.shutdownContext()
 	defer cancel()
+	// HTTP 服务在扣除 ShutdownReserve 后的时间内关闭，慢请求不会占用关闭钩子和组件的时间
+	drainCtx, drainCancel := context.WithTimeout(ctx, e.cfg.ShutdownTimeout-e.shutdownReserve())
+	defer drainCancel()
+
+	if e.health != nil {
+		e.health.draining.Store(true)
+	}
+
+	// 先通知后台任务退出，与 HTTP 服务的关闭并行进行
+	e.workers.cancelAll()
+
+	// WebSocket 连接已被接管，http.Server.Shutdown 不会关闭，需要单独关闭
+	if err := e.hub.shutdown(drainCtx); err != nil {
+		log.Printf("qi: websocket shutdown failed: %v", err)
+	}
 
 	// flush span 数据后再关闭 HTTP server
 	if e.tracingShutdown != nil {
-		if err := e.tracingShutdown(ctx); err != nil {
+		if err := e.tracingShutdown(drainCtx); err != nil {
 			log.Printf("qi: tracing shutdown failed: %v", err)
 		}
 	}
 
-	return e.server.Shutdown(ctx)
+	var errs []error
+	if err := e.server.Shutdown(drainCtx); err != nil {
+		errs = append(errs, fmt.Errorf("qi: http server shutdown: %w", err))
+	}
+	// 后台任务可能依赖组件，组件关闭前等待任务退出
+	if err := e.workers.stop(drainCtx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(append(errs, e.lifecycle.stop(ctx))...)
 }
 
 // buildOpenAPISpec 构建 OpenAPI spec 并注册相关端点。
@@ -292,16 +404,29 @@ func (e *Engine) buildOpenAPISpec() {
 		return
 	}
 
-	// 注册 spec 端点（使用配置的路径）
-	e.engine.GET(cfg.Path, func(c *gin.Context) {
-		c.Data(http.StatusOK, "application/json", docJSON)
-	})
-	e.router.add(Route{
-		Method:      "GET",
-		Path:        cfg.Path,
-		FullPath:    cfg.Path,
-		HandlerName: "qi.OpenAPIJSON",
-	})
+	// 注册 spec 端点（使用配置的路径），支持 ?tag= / ?prefix= 过滤
+	e.addSpecRoute(cfg.Path, "qi.OpenAPIJSON", specHandler(e.api, specFormatJSON, docJSON))
+	if cfg.YAMLPath != cfg.Path {
+		e.addSpecRoute(cfg.YAMLPath, "qi.OpenAPIYAML", specHandler(e.api, specFormatYAML, nil))
+	}
+
+	// 命名子文档：{Path 去扩展名}/{Name}.json|.yaml
+	base := strings.TrimSuffix(cfg.Path, ".json")
+	for _, doc := range cfg.Documents {
+		if doc.Name == "" {
+			continue
+		}
+		opts := []openapi.Option{openapi.WithOperationFilter(doc.filter())}
+		if doc.Title != "" {
+			opts = append(opts, openapi.WithTitle(doc.Title))
+		}
+		if doc.Description != "" {
+			opts = append(opts, openapi.WithDescription(doc.Description))
+		}
+		m := e.api.CloneWithOptions(opts...)
+		e.addSpecRoute(base+"/"+doc.Name+".json", "qi.OpenAPIJSON", specHandler(m, specFormatJSON, nil))
+		e.addSpecRoute(base+"/"+doc.Name+".yaml", "qi.OpenAPIYAML", specHandler(m, specFormatYAML, nil))
+	}
 
 	// 仅当配置了 SwaggerUI 路径时才注册 UI
 	if cfg.SwaggerUI != "" {
@@ -330,6 +455,17 @@ func (e *Engine) buildOpenAPISpec() {
 	}
 }
 
+// addSpecRoute 注册 OpenAPI 相关端点并写入路由表。
+func (e *Engine) addSpecRoute(path, handlerName string, handler gin.HandlerFunc) {
+	e.engine.GET(path, handler)
+	e.router.add(Route{
+		Method:      "GET",
+		Path:        path,
+		FullPath:    path,
+		HandlerName: handlerName,
+	})
+}
+
 func normalizeMode(mode string) string {
 	switch mode {
 	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
@@ -445,7 +581,7 @@ func methodToColor(method string) string {
 	}
 }
 
-// recoveryMiddleware 自定义 panic 恢复中间件，返回 qi 统一 JSON 响应格式
+// recoveryMiddleware 自定义 panic 恢复中间件，按 ResponseRenderer 返回 ErrServer 统一响应
 func recoveryMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -456,8 +592,9 @@ func recoveryMiddleware() gin.HandlerFunc {
 				}
 				// 打印堆栈到日志
 				log.Printf("[QI] panic recovered: %v\n%s", err, debug.Stack())
-				// 返回统一 JSON 响应
-				c.AbortWithStatusJSON(http.StatusInternalServerError, NewResponse(ErrServer.Code, ErrServer.Message, nil))
+				// 返回统一响应
+				c.Abort()
+				(&Context{ctx: c}).Fail(ErrServer)
 			}
 		}()
 		c.Next()
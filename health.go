@@ -0,0 +1,216 @@
+package qi
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 健康状态。
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// HealthChecker 健康检查项，pkg/database、pkg/cache、pkg/mq 均提供了内置实现。
+type HealthChecker interface {
+	Name() string                    // 检查项名称，作为响应中 checks 的 key
+	Check(ctx context.Context) error // 返回 nil 表示健康
+}
+
+// HealthCheck 将函数包装为 HealthChecker。
+//
+// 示例：
+//
+//	e.AddHealthChecker(qi.HealthCheck("orders-db", func(ctx context.Context) error {
+//		return database.Ping(ctx, ordersDB)
+//	}))
+func HealthCheck(name string, fn func(ctx context.Context) error) HealthChecker {
+	return healthCheckFunc{name: name, fn: fn}
+}
+
+type healthCheckFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (h healthCheckFunc) Name() string                    { return h.name }
+func (h healthCheckFunc) Check(ctx context.Context) error { return h.fn(ctx) }
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	LivenessPath  string          // 存活探针路径，默认 /livez，进程能处理请求即返回 200
+	HealthPath    string          // 健康检查路径，默认 /healthz，汇总所有检查项和后台任务状态
+	ReadinessPath string          // 就绪探针路径，默认 /readyz，在 HealthPath 的基础上收到关闭信号后立即返回 503
+	Timeout       time.Duration   // 单个检查项的超时时间，默认 3s
+	DrainDelay    time.Duration   // 收到关闭信号后等待多久再关闭 HTTP 服务，留给负载均衡摘除流量，默认 0
+	Checkers      []HealthChecker // 检查项，也可以通过 Engine.AddHealthChecker 添加，名称不能重复
+}
+
+// WithHealth 注册健康检查端点，cfg 为 nil 时使用默认配置。
+// 端点在全局中间件之前注册，不经过日志、链路追踪和 Use 添加的中间件。
+func WithHealth(cfg *HealthConfig) Option {
+	return func(c *Config) {
+		if cfg == nil {
+			cfg = &HealthConfig{}
+		}
+		c.healthConfig = cfg
+	}
+}
+
+func (c *HealthConfig) normalize() {
+	if c.LivenessPath == "" {
+		c.LivenessPath = "/livez"
+	}
+	if c.HealthPath == "" {
+		c.HealthPath = "/healthz"
+	}
+	if c.ReadinessPath == "" {
+		c.ReadinessPath = "/readyz"
+	}
+	c.LivenessPath = normalizeAbsolutePath(c.LivenessPath)
+	c.HealthPath = normalizeAbsolutePath(c.HealthPath)
+	c.ReadinessPath = normalizeAbsolutePath(c.ReadinessPath)
+	if c.Timeout <= 0 {
+		c.Timeout = 3 * time.Second
+	}
+}
+
+// HealthReport 健康检查响应
+type HealthReport struct {
+	Status  string                 `json:"status"`            // up / down
+	Checks  map[string]CheckResult `json:"checks,omitempty"`  // 各检查项结果
+	Workers []WorkerStatus         `json:"workers,omitempty"` // 后台任务状态，failed 时整体为 down
+}
+
+// CheckResult 单个检查项结果
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"` // 检查耗时
+}
+
+// AddHealthChecker 添加健康检查项，未配置 WithHealth 或检查项名称重复时 panic。
+func (e *Engine) AddHealthChecker(checkers ...HealthChecker) {
+	if e.health == nil {
+		panic("qi: AddHealthChecker requires WithHealth")
+	}
+	e.health.add(checkers...)
+}
+
+// health 健康检查状态。
+type health struct {
+	cfg      *HealthConfig
+	mu       sync.Mutex
+	checkers []HealthChecker
+	draining atomic.Bool // 收到关闭信号后为 true，/readyz 返回 503
+}
+
+// add 添加检查项，名称重复时 panic，避免同名检查项在响应中相互覆盖。
+func (h *health) add(checkers ...HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, checker := range checkers {
+		for _, exist := range h.checkers {
+			if exist.Name() == checker.Name() {
+				panic("qi: duplicate health checker " + checker.Name())
+			}
+		}
+		h.checkers = append(h.checkers, checker)
+	}
+}
+
+// registerHealth 注册健康检查端点。
+func (e *Engine) registerHealth() {
+	cfg := e.cfg.healthConfig
+	cfg.normalize()
+	e.health = &health{cfg: cfg}
+	e.health.add(cfg.Checkers...)
+
+	e.addSpecRoute(cfg.LivenessPath, "qi.Liveness", func(c *gin.Context) {
+		writeHealth(c, &HealthReport{Status: HealthUp})
+	})
+	e.addSpecRoute(cfg.HealthPath, "qi.Health", func(c *gin.Context) {
+		writeHealth(c, e.checkHealth(c.Request.Context()))
+	})
+	e.addSpecRoute(cfg.ReadinessPath, "qi.Readiness", func(c *gin.Context) {
+		if e.health.draining.Load() {
+			writeHealth(c, &HealthReport{Status: HealthDown, Checks: map[string]CheckResult{
+				"shutdown": {Status: HealthDown, Error: "server is shutting down", Duration: "0s"},
+			}})
+			return
+		}
+		writeHealth(c, e.checkHealth(c.Request.Context()))
+	})
+}
+
+// checkHealth 并发执行所有检查项并汇总后台任务状态。
+func (e *Engine) checkHealth(ctx context.Context) *HealthReport {
+	e.health.mu.Lock()
+	checkers := append([]HealthChecker(nil), e.health.checkers...)
+	e.health.mu.Unlock()
+
+	report := &HealthReport{Status: HealthUp, Workers: e.Workers()}
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			begin := time.Now()
+			results[i] = CheckResult{Status: HealthUp}
+			if err := runHook(ctx, e.health.cfg.Timeout, checker.Check); err != nil {
+				results[i] = CheckResult{Status: HealthDown, Error: err.Error()}
+			}
+			results[i].Duration = time.Since(begin).Round(time.Microsecond).String()
+		}()
+	}
+	wg.Wait()
+
+	if len(checkers) > 0 {
+		report.Checks = make(map[string]CheckResult, len(checkers))
+	}
+	for i, checker := range checkers {
+		report.Checks[checker.Name()] = results[i]
+		if results[i].Status == HealthDown {
+			report.Status = HealthDown
+		}
+	}
+	for _, w := range report.Workers {
+		if w.State == WorkerFailed {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+// drain 将就绪状态置为失败，并等待 DrainDelay 让负载均衡摘除流量，期间再次收到信号时立即返回。
+func (e *Engine) drain(quit <-chan os.Signal) {
+	if e.health == nil {
+		return
+	}
+	e.health.draining.Store(true)
+	if e.health.cfg.DrainDelay <= 0 {
+		return
+	}
+	timer := time.NewTimer(e.health.cfg.DrainDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-quit:
+	}
+}
+
+func writeHealth(c *gin.Context, report *HealthReport) {
+	status := http.StatusOK
+	if report.Status == HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
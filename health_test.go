@@ -0,0 +1,116 @@
+package qi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEngine_Health(t *testing.T) {
+	var logged []string
+	e := New(WithMode("test"), WithHealth(&HealthConfig{
+		Timeout:  20 * time.Millisecond,
+		Checkers: []HealthChecker{HealthCheck("db", func(context.Context) error { return nil })},
+	}))
+	// 探针请求不经过全局中间件
+	e.Use(func(c *Context) {
+		logged = append(logged, c.Request().URL.Path)
+		c.Fail(ErrUnauthorized)
+		c.Abort()
+	})
+	cacheErr := error(nil)
+	e.AddHealthChecker(HealthCheck("cache", func(context.Context) error { return cacheErr }))
+
+	get := func(path string) (int, HealthReport) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var report HealthReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: decode %q: %v", path, w.Body.String(), err)
+		}
+		return w.Code, report
+	}
+
+	if code, r := get("/healthz"); code != http.StatusOK || r.Status != HealthUp || len(r.Checks) != 2 || r.Checks["db"].Status != HealthUp {
+		t.Errorf("healthz = %d %+v", code, r)
+	}
+
+	// 单项失败或超时时整体为 down，存活探针不受影响
+	cacheErr = errors.New("redis: connection refused")
+	e.AddHealthChecker(HealthCheck("mq", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	code, r := get("/readyz")
+	if code != http.StatusServiceUnavailable || r.Status != HealthDown ||
+		r.Checks["cache"].Error != "redis: connection refused" || r.Checks["mq"].Error != context.DeadlineExceeded.Error() ||
+		r.Checks["db"].Status != HealthUp {
+		t.Errorf("readyz = %d %+v", code, r)
+	}
+	if code, r := get("/livez"); code != http.StatusOK || r.Status != HealthUp {
+		t.Errorf("livez = %d %+v", code, r)
+	}
+	if len(logged) != 0 {
+		t.Errorf("global middleware ran for %v", logged)
+	}
+}
+
+func TestEngine_HealthConfig(t *testing.T) {
+	e := New(WithMode("test"), WithHealth(&HealthConfig{LivenessPath: "live/", HealthPath: "//health"}))
+	for _, path := range []string{"/live", "/health", "/readyz"} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d", path, w.Code)
+		}
+	}
+
+	// 同名检查项会在响应中相互覆盖
+	e.AddHealthChecker(HealthCheck("db", func(context.Context) error { return nil }))
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate health checker should panic")
+		}
+	}()
+	e.AddHealthChecker(HealthCheck("db", func(context.Context) error { return errors.New("down") }))
+}
+
+func TestEngine_HealthWorkersAndDrain(t *testing.T) {
+	e := New(WithMode("test"), WithHealth(nil))
+	e.Go("consumer", func(context.Context) error { return errors.New("auth failed") })
+	e.workers.start()
+	waitFor(t, func() bool { return e.Workers()[0].State == WorkerFailed })
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	var r HealthReport
+	_ = json.Unmarshal(w.Body.Bytes(), &r)
+	if w.Code != http.StatusServiceUnavailable || len(r.Workers) != 1 || r.Workers[0].LastError != "auth failed" {
+		t.Errorf("healthz = %d %s", w.Code, w.Body.String())
+	}
+
+	// 收到关闭信号后就绪探针立即失败，健康检查不受影响
+	e.drain(nil)
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != `{"status":"down","checks":{"shutdown":{"status":"down","error":"server is shutting down","duration":"0s"}}}` {
+		t.Errorf("readyz after drain = %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("livez after drain = %d", w.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("AddHealthChecker without WithHealth should panic")
+		}
+	}()
+	New(WithMode("test")).AddHealthChecker(HealthCheck("db", nil))
+}
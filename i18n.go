@@ -0,0 +1,287 @@
+package qi
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tokmz/qi/pkg/i18n"
+)
+
+// gin.Context 中存放国际化信息的键。
+const (
+	localeKey     = "qi.locale"
+	i18nBundleKey = "qi.i18n"
+)
+
+// I18nConfig 国际化配置。启用后 Context.Fail 按请求语言输出业务错误消息和字段校验消息。
+type I18nConfig struct {
+	Bundle        *i18n.Bundle                 // 消息目录，为 nil 时使用 NewI18nBundle 创建的内置 zh-CN / en 目录
+	DefaultLocale string                       // 无法协商时使用的语言区域，默认 "zh-CN"
+	QueryParam    string                       // 指定语言的查询参数名，默认 "lang"，优先于 Accept-Language
+	Messages      map[string]map[int]string    // 追加的业务错误码消息，locale -> code -> message
+	Rules         map[string]map[string]string // 追加的校验规则消息，locale -> rule -> template
+}
+
+// WithI18n 启用国际化，语言区域按 QueryParam 查询参数 > Accept-Language > DefaultLocale 协商。
+func WithI18n(cfg *I18nConfig) Option {
+	return func(c *Config) {
+		c.i18nConfig = cfg
+	}
+}
+
+func (c *I18nConfig) normalize() {
+	if c.DefaultLocale == "" {
+		c.DefaultLocale = "zh-CN"
+	}
+	if c.QueryParam == "" {
+		c.QueryParam = "lang"
+	}
+	if c.Bundle == nil {
+		c.Bundle = NewI18nBundle(c.DefaultLocale)
+	}
+	for locale, msgs := range c.Messages {
+		c.Bundle.AddCodes(locale, msgs)
+	}
+	for locale, rules := range c.Rules {
+		c.Bundle.AddRules(locale, rules)
+	}
+}
+
+// NewI18nBundle 创建包含内置 zh-CN / en 消息的目录：预定义错误码（ErrServer 等）和常用校验规则。
+func NewI18nBundle(defaultLocale string) *i18n.Bundle {
+	b := i18n.New(defaultLocale)
+	for locale, msgs := range builtinCodeMessages {
+		b.AddCodes(locale, msgs)
+	}
+	for locale, rules := range builtinRuleMessages {
+		b.AddRules(locale, rules)
+	}
+	return b
+}
+
+// i18nMiddleware 协商请求的语言区域并写入 gin.Context。
+func i18nMiddleware(cfg *I18nConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		candidates := make([]string, 0, 4)
+		if lang := c.Query(cfg.QueryParam); lang != "" {
+			candidates = append(candidates, lang)
+		}
+		candidates = append(candidates, i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"))...)
+		c.Set(i18nBundleKey, cfg.Bundle)
+		c.Set(localeKey, cfg.Bundle.Match(candidates...))
+		c.Next()
+	}
+}
+
+// Locale 返回当前请求协商出的语言区域，如 "zh-CN"；未启用 WithI18n 时返回空字符串。
+func (c *Context) Locale() string {
+	return c.ctx.GetString(localeKey)
+}
+
+// i18nBundle 返回当前请求使用的消息目录，未启用时返回 nil。
+func (c *Context) i18nBundle() *i18n.Bundle {
+	if v, ok := c.ctx.Get(i18nBundleKey); ok {
+		b, _ := v.(*i18n.Bundle)
+		return b
+	}
+	return nil
+}
+
+// localize 返回业务错误码的本地化消息；未启用国际化或消息为自定义内容时原样返回。
+func (c *Context) localize(code int, message string) string {
+	if b := c.i18nBundle(); b != nil {
+		return b.Localize(c.Locale(), code, message)
+	}
+	return message
+}
+
+// ruleMessage 返回校验规则的消息：优先使用当前语言区域的目录，其次内置英文消息。
+// unit 为 "length"（字符串长度）、"items"（元素个数）或空（数值比较）。
+func (c *Context) ruleMessage(rule, unit, param string) string {
+	if b := c.i18nBundle(); b != nil {
+		if msg, ok := b.Rule(c.Locale(), rule, unit, param); ok {
+			return msg
+		}
+	}
+	return defaultRuleMessage(rule, unit, param)
+}
+
+// localizeFieldErrors 按当前语言区域改写字段错误消息，目录中没有的规则保留原消息。
+func (c *Context) localizeFieldErrors(fields ValidationErrors) {
+	b := c.i18nBundle()
+	if b == nil {
+		return
+	}
+	for i, fe := range fields {
+		if msg, ok := b.Rule(c.Locale(), fe.Rule, "", fe.Param); ok {
+			fields[i].Message = msg
+		}
+	}
+}
+
+// defaultRuleMessage 返回内置英文规则消息。
+func defaultRuleMessage(rule, unit, param string) string {
+	en := builtinRuleMessages["en"]
+	if tmpl, ok := en[rule+"."+unit]; ok && unit != "" {
+		return i18n.Render(tmpl, param)
+	}
+	if tmpl, ok := en[rule]; ok {
+		return i18n.Render(tmpl, param)
+	}
+	if param != "" {
+		return "failed on the '" + rule + "=" + param + "' rule"
+	}
+	return "failed on the '" + rule + "' rule"
+}
+
+// builtinCodeMessages 预定义错误码的内置消息，en 与 errors.go 中的默认消息一致。
+var builtinCodeMessages = map[string]map[int]string{
+	"en": {
+		1000: "server error",
+		1001: "bad request",
+		1002: "unauthorized",
+		1003: "forbidden",
+		1004: "not found",
+		1005: "conflict",
+		1006: "too many requests",
+		1100: "invalid parameters",
+		1101: "missing parameters",
+		1102: "invalid format",
+		1103: "out of range",
+	},
+	"zh-CN": {
+		1000: "服务器错误",
+		1001: "请求参数错误",
+		1002: "未授权",
+		1003: "禁止访问",
+		1004: "资源不存在",
+		1005: "资源冲突",
+		1006: "请求过于频繁",
+		1100: "参数无效",
+		1101: "缺少参数",
+		1102: "格式错误",
+		1103: "超出范围",
+	},
+}
+
+// builtinRuleMessages 内置校验规则消息，包含 validator tag（min、oneof 等）和 OpenAPI 校验关键字（minLength、enum 等）。
+var builtinRuleMessages = map[string]map[string]string{
+	"en": {
+		"required":         "is required",
+		"min":              "must be >= {param}",
+		"min.length":       "length must be >= {param}",
+		"min.items":        "item count must be >= {param}",
+		"gte":              "must be >= {param}",
+		"gte.length":       "length must be >= {param}",
+		"gte.items":        "item count must be >= {param}",
+		"max":              "must be <= {param}",
+		"max.length":       "length must be <= {param}",
+		"max.items":        "item count must be <= {param}",
+		"lte":              "must be <= {param}",
+		"lte.length":       "length must be <= {param}",
+		"lte.items":        "item count must be <= {param}",
+		"gt":               "must be > {param}",
+		"gt.length":        "length must be > {param}",
+		"gt.items":         "item count must be > {param}",
+		"lt":               "must be < {param}",
+		"lt.length":        "length must be < {param}",
+		"lt.items":         "item count must be < {param}",
+		"len":              "must be {param}",
+		"len.length":       "length must be {param}",
+		"len.items":        "item count must be {param}",
+		"eq":               "must be equal to {param}",
+		"ne":               "must not be equal to {param}",
+		"oneof":            "must be one of [{list}]",
+		"email":            "must be a valid email",
+		"url":              "must be a valid url",
+		"uri":              "must be a valid uri",
+		"uuid":             "must be a valid uuid",
+		"ip":               "must be a valid ip",
+		"ipv4":             "must be a valid ipv4",
+		"ipv6":             "must be a valid ipv6",
+		"datetime":         "must be a valid datetime ({param})",
+		"alpha":            "must contain only letters",
+		"alphanum":         "must contain only letters and digits",
+		"numeric":          "must be numeric",
+		"number":           "must be a number",
+		"type":             "must be of type {param}",
+		"format":           "must be a valid {param}",
+		"nullable":         "must not be null",
+		"enum":             "must be one of [{list}]",
+		"pattern":          "must match pattern {param}",
+		"minLength":        "length must be at least {param}",
+		"maxLength":        "length must be at most {param}",
+		"minItems":         "item count must be at least {param}",
+		"maxItems":         "item count must be at most {param}",
+		"minimum":          "must be >= {param}",
+		"maximum":          "must be <= {param}",
+		"exclusiveMinimum": "must be > {param}",
+		"exclusiveMaximum": "must be < {param}",
+		"contentType":      "unsupported content type {param}",
+		"discriminator":    "unknown variant {param}",
+		"oneOf":            "does not match any allowed schema",
+		"anyOf":            "does not match any allowed schema",
+		"phone":            "must be a valid mobile phone number",
+		"idcard":           "must be a valid ID card number",
+		"creditcode":       "must be a valid unified social credit code",
+		"bankcard":         "must be a valid bank card number",
+	},
+	"zh-CN": {
+		"required":         "不能为空",
+		"min":              "不能小于 {param}",
+		"min.length":       "长度不能小于 {param}",
+		"min.items":        "至少包含 {param} 项",
+		"gte":              "不能小于 {param}",
+		"gte.length":       "长度不能小于 {param}",
+		"gte.items":        "至少包含 {param} 项",
+		"max":              "不能大于 {param}",
+		"max.length":       "长度不能大于 {param}",
+		"max.items":        "最多包含 {param} 项",
+		"lte":              "不能大于 {param}",
+		"lte.length":       "长度不能大于 {param}",
+		"lte.items":        "最多包含 {param} 项",
+		"gt":               "必须大于 {param}",
+		"gt.length":        "长度必须大于 {param}",
+		"gt.items":         "必须多于 {param} 项",
+		"lt":               "必须小于 {param}",
+		"lt.length":        "长度必须小于 {param}",
+		"lt.items":         "必须少于 {param} 项",
+		"len":              "必须等于 {param}",
+		"len.length":       "长度必须为 {param}",
+		"len.items":        "必须包含 {param} 项",
+		"eq":               "必须等于 {param}",
+		"ne":               "不能等于 {param}",
+		"oneof":            "必须是 [{list}] 之一",
+		"email":            "不是有效的邮箱地址",
+		"url":              "不是有效的 URL",
+		"uri":              "不是有效的 URI",
+		"uuid":             "不是有效的 UUID",
+		"ip":               "不是有效的 IP 地址",
+		"ipv4":             "不是有效的 IPv4 地址",
+		"ipv6":             "不是有效的 IPv6 地址",
+		"datetime":         "不是有效的时间（{param}）",
+		"alpha":            "只能包含字母",
+		"alphanum":         "只能包含字母和数字",
+		"numeric":          "必须是数字",
+		"number":           "必须是数字",
+		"type":             "类型必须为 {param}",
+		"format":           "不是有效的 {param} 格式",
+		"nullable":         "不能为 null",
+		"enum":             "必须是 [{list}] 之一",
+		"pattern":          "格式不正确",
+		"minLength":        "长度不能小于 {param}",
+		"maxLength":        "长度不能大于 {param}",
+		"minItems":         "至少包含 {param} 项",
+		"maxItems":         "最多包含 {param} 项",
+		"minimum":          "不能小于 {param}",
+		"maximum":          "不能大于 {param}",
+		"exclusiveMinimum": "必须大于 {param}",
+		"exclusiveMaximum": "必须小于 {param}",
+		"contentType":      "不支持的内容类型 {param}",
+		"discriminator":    "未知的类型 {param}",
+		"oneOf":            "不符合任何允许的结构",
+		"anyOf":            "不符合任何允许的结构",
+		"phone":            "不是有效的手机号",
+		"idcard":           "不是有效的身份证号",
+		"creditcode":       "不是有效的统一社会信用代码",
+		"bankcard":         "不是有效的银行卡号",
+	},
+}
@@ -0,0 +1,130 @@
+package qi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tokmz/qi/pkg/errors"
+)
+
+func TestI18n_Fail(t *testing.T) {
+	errUserExists := errors.NewWithStatus(20001, http.StatusConflict, "user already exists")
+	e := New(WithMode("test"), WithI18n(&I18nConfig{
+		Messages: map[string]map[int]string{
+			"zh-CN": {20001: "用户已存在"},
+			"en":    {20001: "user already exists"},
+		},
+	}))
+	e.GET("/missing", func(c *Context) { c.Fail(ErrNotFound) })
+	e.GET("/custom", func(c *Context) { c.Fail(ErrNotFound.WithMessage("order 42 not found")) })
+	e.GET("/exists", func(c *Context) { c.Fail(errUserExists) })
+	e.GET("/locale", func(c *Context) { c.OK(c.Locale()) })
+
+	get := func(path, acceptLanguage string) map[string]any {
+		req := httptest.NewRequest("GET", path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		m, err := parseResponse(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		return m
+	}
+
+	tests := []struct {
+		path, lang, field, want string
+	}{
+		{"/missing", "", "message", "资源不存在"},
+		{"/missing", "en-US,en;q=0.9", "message", "not found"},
+		{"/missing?lang=en", "zh-CN", "message", "not found"},
+		{"/custom", "zh-CN", "message", "order 42 not found"},
+		{"/exists", "zh", "message", "用户已存在"},
+		{"/locale", "fr, en;q=0.5", "data", "en"},
+	}
+	for _, tt := range tests {
+		if got := get(tt.path, tt.lang)[tt.field]; got != tt.want {
+			t.Errorf("GET %s (%s): %s = %v, want %q", tt.path, tt.lang, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestI18n_BindFieldErrors(t *testing.T) {
+	e := New(WithMode("test"), WithI18n(&I18nConfig{}))
+	e.API().POST("/orders", Bind(func(c *Context, req *orderReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{"name":"a","items":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	m, _ := parseResponse(w.Body.Bytes())
+	if m["message"] != "请求参数错误" {
+		t.Errorf("message = %v", m["message"])
+	}
+	data, _ := m["data"].(map[string]any)
+	list, _ := data["errors"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["message"] != "长度不能小于 2" {
+		t.Errorf("errors = %v", list)
+	}
+}
+
+type merchantReq struct {
+	Phone      string `json:"phone" binding:"required,phone"`
+	IDCard     string `json:"id_card" binding:"omitempty,idcard"`
+	CreditCode string `json:"credit_code" binding:"omitempty,creditcode"`
+	BankCard   string `json:"bank_card" binding:"omitempty,bankcard"`
+}
+
+func TestI18n_BuiltinValidationMessages(t *testing.T) {
+	e := New(WithMode("test"), WithI18n(&I18nConfig{}))
+	e.API().POST("/companies", Bind(func(c *Context, req *merchantReq) (*merchantReq, error) {
+		return req, nil
+	})).Done()
+
+	messages := func(lang string) map[string]string {
+		req := httptest.NewRequest("POST", "/companies", strings.NewReader(`{"phone":"12345","id_card":"123","credit_code":"abc","bank_card":"12ab"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		m, _ := parseResponse(w.Body.Bytes())
+		data, _ := m["data"].(map[string]any)
+		list, _ := data["errors"].([]any)
+		out := make(map[string]string)
+		for _, item := range list {
+			fe := item.(map[string]any)
+			out[fe["field"].(string)] = fe["message"].(string)
+		}
+		return out
+	}
+
+	tests := map[string]map[string]string{
+		"zh-CN": {
+			"phone":       "不是有效的手机号",
+			"id_card":     "不是有效的身份证号",
+			"credit_code": "不是有效的统一社会信用代码",
+			"bank_card":   "不是有效的银行卡号",
+		},
+		"en": {
+			"phone":       "must be a valid mobile phone number",
+			"id_card":     "must be a valid ID card number",
+			"credit_code": "must be a valid unified social credit code",
+			"bank_card":   "must be a valid bank card number",
+		},
+	}
+	for lang, want := range tests {
+		got := messages(lang)
+		for field, msg := range want {
+			if got[field] != msg {
+				t.Errorf("%s %s message = %q, want %q", lang, field, got[field], msg)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"fmt"
+	"mime/multipart"
 	"reflect"
 	"sort"
 	"strings"
@@ -14,6 +15,7 @@ type AnalyzeOptions struct {
 	FieldNamer          FieldNamer
 	DescriptionProvider DescriptionProvider
 	ConstraintParser    ConstraintParser
+	Unions              map[reflect.Type]Union
 }
 
 type Analyzer struct {
@@ -61,6 +63,11 @@ func (a *Analyzer) AnalyzeBody(v any) (*SchemaNode, error) {
 	return a.analyzeRoot(typeOf(v), AnalyzeModeBody)
 }
 
+// AnalyzeForm 按 form tag 分析表单请求体（multipart/form-data、application/x-www-form-urlencoded）。
+func (a *Analyzer) AnalyzeForm(v any) (*SchemaNode, error) {
+	return a.analyzeRoot(typeOf(v), AnalyzeModeForm)
+}
+
 func (a *Analyzer) AnalyzeResponse(v any) (*SchemaNode, error) {
 	return a.analyzeRoot(typeOf(v), AnalyzeModeResponse)
 }
@@ -134,6 +141,10 @@ func (a *Analyzer) analyzeSchema(t reflect.Type, mode AnalyzeMode) (*SchemaNode,
 }
 
 func (a *Analyzer) analyzeSchemaUncached(t reflect.Type, mode AnalyzeMode) (*SchemaNode, error) {
+	if isFileHeaderType(t) {
+		return &SchemaNode{Type: "string", Format: "binary", GoType: t}, nil
+	}
+
 	if t.Kind() == reflect.Ptr {
 		node, err := a.analyzeSchema(t.Elem(), mode)
 		if err != nil {
@@ -148,6 +159,10 @@ func (a *Analyzer) analyzeSchemaUncached(t reflect.Type, mode AnalyzeMode) (*Sch
 		return &SchemaNode{Type: "string", Format: "date-time", GoType: t}, nil
 	}
 
+	if u, ok := a.opts.Unions[t]; ok {
+		return a.analyzeUnion(t, u, mode)
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
 		return &SchemaNode{Type: "boolean", GoType: t}, nil
@@ -187,7 +202,8 @@ func (a *Analyzer) analyzeSchemaUncached(t reflect.Type, mode AnalyzeMode) (*Sch
 		}
 		return &SchemaNode{Type: "object", AdditionalProperties: value, GoType: t}, nil
 	case reflect.Interface:
-		return &SchemaNode{Type: "object", GoType: t}, nil
+		// 未注册 Union 的接口可以是任意 JSON 值
+		return &SchemaNode{GoType: t}, nil
 	case reflect.Struct:
 		if shouldUseComponent(t) {
 			return a.analyzeNamedStruct(t, mode)
@@ -199,6 +215,13 @@ func (a *Analyzer) analyzeSchemaUncached(t reflect.Type, mode AnalyzeMode) (*Sch
 }
 
 func (a *Analyzer) analyzeNamedStruct(t reflect.Type, mode AnalyzeMode) (*SchemaNode, error) {
+	return a.analyzeComponent(t, mode, func() (*SchemaNode, error) {
+		return a.buildInlineStructSchema(t, mode)
+	})
+}
+
+// analyzeComponent 将 build 生成的 schema 注册为组件并返回引用，递归引用自身时直接返回引用。
+func (a *Analyzer) analyzeComponent(t reflect.Type, mode AnalyzeMode, build func() (*SchemaNode, error)) (*SchemaNode, error) {
 	name := schemaNameForMode(a.opts.NameResolver.SchemaName(t), mode)
 	compKey := componentKey{Name: name, Mode: mode}
 	buildKey := componentBuildKey{Type: t, Mode: mode}
@@ -222,8 +245,11 @@ func (a *Analyzer) analyzeNamedStruct(t reflect.Type, mode AnalyzeMode) (*Schema
 	a.building[buildKey] = name
 	a.mu.Unlock()
 
-	node, err := a.buildInlineStructSchema(t, mode)
+	node, err := build()
 	if err != nil {
+		a.mu.Lock()
+		delete(a.building, buildKey)
+		a.mu.Unlock()
 		return nil, err
 	}
 	node.Name = name
@@ -324,6 +350,8 @@ func (a *Analyzer) buildStructProperties(owner, current reflect.Type, mode Analy
 
 func (a *Analyzer) buildParameters(owner, current reflect.Type, mode AnalyzeMode, in ParamIn) ([]ParameterSpec, error) {
 	var params []ParameterSpec
+	// 声明了 form tag 的结构体同时包含查询参数和请求体，仅有 json tag 的字段属于请求体
+	splitBody := mode == AnalyzeModeQuery && structHasTag(owner, "form")
 
 	for i := 0; i < current.NumField(); i++ {
 		field := current.Field(i)
@@ -340,6 +368,10 @@ func (a *Analyzer) buildParameters(owner, current reflect.Type, mode AnalyzeMode
 			continue
 		}
 
+		if splitBody && field.Tag.Get("form") == "" && field.Tag.Get("json") != "" {
+			continue
+		}
+
 		tagInfo, err := ParseFieldTags(field, mode, a.opts.FieldNamer)
 		if err != nil {
 			return nil, err
@@ -459,6 +491,8 @@ func schemaNameForMode(base string, mode AnalyzeMode) string {
 		return base + ".Cookie"
 	case AnalyzeModeResponse:
 		return base + ".Response"
+	case AnalyzeModeForm:
+		return base + ".Form"
 	default:
 		return base + ".Body"
 	}
@@ -488,10 +522,33 @@ func isEmbeddable(t reflect.Type) bool {
 	return base.Kind() == reflect.Struct && !isTimeType(base)
 }
 
+// structHasTag 检查结构体字段（含嵌入）是否声明了指定 tag。
+func structHasTag(t reflect.Type, tag string) bool {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+		if field.Anonymous && structHasTag(field.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 func isTimeType(t reflect.Type) bool {
 	return t == reflect.TypeOf(time.Time{})
 }
 
+// isFileHeaderType 判断是否为上传文件类型（multipart.FileHeader 或其指针）。
+func isFileHeaderType(t reflect.Type) bool {
+	return indirectType(t) == reflect.TypeOf(multipart.FileHeader{})
+}
+
 func modeFromParamIn(in ParamIn) AnalyzeMode {
 	switch in {
 	case ParamInPath:
@@ -580,6 +637,13 @@ func cloneNode(node *SchemaNode) *SchemaNode {
 	if node.AdditionalProperties != nil {
 		out.AdditionalProperties = cloneNode(node.AdditionalProperties)
 	}
+	out.OneOf = cloneNodes(node.OneOf)
+	out.AnyOf = cloneNodes(node.AnyOf)
+	if node.Discriminator != nil {
+		d := *node.Discriminator
+		d.Mapping = cloneStringMap(node.Discriminator.Mapping)
+		out.Discriminator = &d
+	}
 	if len(node.Constraints.Raw) > 0 {
 		out.Constraints.Raw = make(map[string]string, len(node.Constraints.Raw))
 		for k, v := range node.Constraints.Raw {
@@ -589,6 +653,17 @@ func cloneNode(node *SchemaNode) *SchemaNode {
 	return &out
 }
 
+func cloneNodes(nodes []*SchemaNode) []*SchemaNode {
+	if nodes == nil {
+		return nil
+	}
+	out := make([]*SchemaNode, len(nodes))
+	for i, n := range nodes {
+		out[i] = cloneNode(n)
+	}
+	return out
+}
+
 func wrapRefNode(node *SchemaNode, mutate func(*SchemaNode)) *SchemaNode {
 	if node == nil || node.Ref == "" {
 		if mutate != nil && node != nil {
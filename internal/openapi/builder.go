@@ -3,6 +3,7 @@ package openapi
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -59,8 +60,16 @@ func NewBuilder(opts Options) *Builder {
 }
 
 func (b *Builder) Build(reg *Registry, analyzer *Analyzer) (*Document, error) {
+	version := b.opts.OpenAPIVersion
+	if version == "" {
+		version = Version30
+	}
+	is31, err := checkOpenAPIVersion(version)
+	if err != nil {
+		return nil, err
+	}
 	doc := &Document{
-		OpenAPI: "3.0.3",
+		OpenAPI: version,
 		Info: Info{
 			Title:       b.opts.Title,
 			Description: b.opts.Description,
@@ -70,15 +79,23 @@ func (b *Builder) Build(reg *Registry, analyzer *Analyzer) (*Document, error) {
 		Paths:   make(map[string]*PathItem),
 	}
 
+	env, err := b.newEnvelopeState(analyzer)
+	if err != nil {
+		return nil, err
+	}
+
 	tagSet := make(map[string]struct{})
 	for _, op := range reg.List() {
+		if b.opts.OperationFilter != nil && !b.opts.OperationFilter(op) {
+			continue
+		}
 		pathItem := doc.Paths[op.Path]
 		if pathItem == nil {
 			pathItem = &PathItem{}
 			doc.Paths[op.Path] = pathItem
 		}
 
-		opObj, err := b.buildOperation(op, analyzer)
+		opObj, err := b.buildOperation(op, analyzer, env)
 		if err != nil {
 			return nil, err
 		}
@@ -120,6 +137,15 @@ func (b *Builder) Build(reg *Registry, analyzer *Analyzer) (*Document, error) {
 		doc.Components.Schemas[name] = schema
 	}
 
+	if env != nil {
+		if doc.Components.Schemas == nil {
+			doc.Components.Schemas = make(map[string]*Schema, len(env.components))
+		}
+		for name, schema := range env.components {
+			doc.Components.Schemas[name] = schema
+		}
+	}
+
 	if len(b.opts.SecuritySchemes) > 0 {
 		if doc.Components.SecuritySchemes == nil {
 			doc.Components.SecuritySchemes = make(map[string]*SecurityScheme, len(b.opts.SecuritySchemes))
@@ -139,10 +165,13 @@ func (b *Builder) Build(reg *Registry, analyzer *Analyzer) (*Document, error) {
 		doc.Tags = append(doc.Tags, Tag{Name: tag})
 	}
 
+	if is31 {
+		upgradeDocument31(doc)
+	}
 	return doc, nil
 }
 
-func (b *Builder) buildOperation(op Operation, analyzer *Analyzer) (*OperationObject, error) {
+func (b *Builder) buildOperation(op Operation, analyzer *Analyzer, env *envelopeState) (*OperationObject, error) {
 	out := &OperationObject{
 		OperationID: op.OperationID,
 		Summary:     op.Summary,
@@ -153,33 +182,55 @@ func (b *Builder) buildOperation(op Operation, analyzer *Analyzer) (*OperationOb
 	}
 
 	if req := op.Request; req != nil {
-		for _, part := range []struct {
+		type paramPart struct {
 			value any
 			in    ParamIn
-		}{
-			{value: req.PathParams, in: ParamInPath},
-			{value: req.QueryParams, in: ParamInQuery},
-			{value: req.Headers, in: ParamInHeader},
-			{value: req.Cookies, in: ParamInCookie},
-		} {
+		}
+		parts := make([]paramPart, 0, 4+len(req.CommonHeaders)+len(req.CommonCookies))
+		for _, v := range req.CommonHeaders {
+			parts = append(parts, paramPart{value: v, in: ParamInHeader})
+		}
+		for _, v := range req.CommonCookies {
+			parts = append(parts, paramPart{value: v, in: ParamInCookie})
+		}
+		parts = append(parts,
+			paramPart{value: req.PathParams, in: ParamInPath},
+			paramPart{value: req.QueryParams, in: ParamInQuery},
+			paramPart{value: req.Headers, in: ParamInHeader},
+			paramPart{value: req.Cookies, in: ParamInCookie},
+		)
+
+		// 同一位置的同名参数以后出现的为准
+		index := make(map[string]int)
+		for _, part := range parts {
 			params, err := analyzer.AnalyzeParameters(part.value, part.in)
 			if err != nil {
 				return nil, err
 			}
 			for _, param := range params {
+				key := string(param.In) + ":" + param.Name
+				if i, ok := index[key]; ok {
+					out.Parameters[i] = b.buildParameter(param)
+					continue
+				}
+				index[key] = len(out.Parameters)
 				out.Parameters = append(out.Parameters, b.buildParameter(param))
 			}
 		}
 
 		if req.Body != nil {
-			schemaNode, err := analyzer.AnalyzeBody(req.Body)
-			if err != nil {
-				return nil, err
-			}
 			contentType := req.BodyContentType
 			if contentType == "" {
 				contentType = "application/json"
 			}
+			analyze := analyzer.AnalyzeBody
+			if isFormContentType(contentType) {
+				analyze = analyzer.AnalyzeForm
+			}
+			schemaNode, err := analyze(req.Body)
+			if err != nil {
+				return nil, err
+			}
 			out.RequestBody = &RequestBody{
 				Required: req.BodyRequired,
 				Content: map[string]*MediaType{
@@ -208,19 +259,34 @@ func (b *Builder) buildOperation(op Operation, analyzer *Analyzer) (*OperationOb
 					item.Description = "Response"
 				}
 			}
+			var schema *Schema
 			if resp.Body != nil {
 				schemaNode, err := analyzer.AnalyzeResponse(resp.Body)
 				if err != nil {
 					return nil, err
 				}
-				contentType := resp.ContentType
-				if contentType == "" {
-					contentType = "application/json"
+				schema = b.buildSchema(schemaNode)
+				if env != nil && !resp.Raw {
+					schema = env.wrap(schemaNode, schema)
 				}
-				item.Content = map[string]*MediaType{
-					contentType: {
-						Schema: b.buildSchema(schemaNode),
-					},
+			} else if env != nil && !resp.Raw {
+				schema = &Schema{Ref: env.ref}
+			}
+			if schema != nil || len(resp.Examples) > 0 {
+				contentTypes := resp.MediaTypes
+				if len(contentTypes) == 0 {
+					contentType := resp.ContentType
+					if contentType == "" {
+						contentType = "application/json"
+					}
+					contentTypes = []string{contentType}
+				}
+				item.Content = make(map[string]*MediaType, len(contentTypes))
+				for _, contentType := range contentTypes {
+					item.Content[contentType] = &MediaType{
+						Schema:   schema,
+						Examples: buildExamples(resp.Examples),
+					}
 				}
 			}
 			out.Responses[key] = item
@@ -230,7 +296,7 @@ func (b *Builder) buildOperation(op Operation, analyzer *Analyzer) (*OperationOb
 	for _, sec := range op.Security {
 		item := make(map[string][]string, len(sec))
 		for k, v := range sec {
-			item[k] = append([]string(nil), v...)
+			item[k] = append([]string{}, v...)
 		}
 		out.Security = append(out.Security, item)
 	}
@@ -244,6 +310,87 @@ func (b *Builder) buildOperation(op Operation, analyzer *Analyzer) (*OperationOb
 	return out, nil
 }
 
+// envelopeState 保存单次 Build 内统一响应包装的基础组件和按业务类型生成的包装组件。
+type envelopeState struct {
+	env        *Envelope
+	ref        string
+	components map[string]*Schema
+}
+
+// newEnvelopeState 分析包装结构体并生成基础组件，DataField 属性放宽为任意类型。
+// 未配置 ResponseEnvelope 时返回 nil。
+func (b *Builder) newEnvelopeState(analyzer *Analyzer) (*envelopeState, error) {
+	env := b.opts.ResponseEnvelope
+	if env == nil {
+		return nil, nil
+	}
+	t := indirectType(typeOf(env.Type))
+	if t == nil || t.Kind() != reflect.Struct || isTimeType(t) {
+		return nil, fmt.Errorf("openapi: response envelope must be a struct, got %v", t)
+	}
+	node, err := analyzer.buildInlineStructSchema(t, AnalyzeModeResponse)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := node.Properties[env.DataField]
+	if !ok {
+		return nil, fmt.Errorf("openapi: response envelope %s has no property %q", t.String(), env.DataField)
+	}
+	node.Properties[env.DataField] = &SchemaNode{Description: data.Description}
+
+	return &envelopeState{
+		env:        env,
+		ref:        "#/components/schemas/" + env.Name,
+		components: map[string]*Schema{env.Name: b.buildSchema(node)},
+	}, nil
+}
+
+// wrap 将业务数据 schema 包装进统一响应结构。
+// 业务类型为组件时生成具名包装组件（如 Response_pkg.User），便于客户端生成器产出独立类型；
+// 否则内联 allOf。
+func (s *envelopeState) wrap(node *SchemaNode, data *Schema) *Schema {
+	wrapped := &Schema{
+		AllOf: []*Schema{
+			{Ref: s.ref},
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{s.env.DataField: data},
+			},
+		},
+	}
+	if node == nil || node.Ref == "" || node.Name == "" {
+		return wrapped
+	}
+	name := s.env.Name + "_" + strings.TrimSuffix(node.Name, ".Response")
+	s.components[name] = wrapped
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// isFormContentType 判断请求体是否为表单编码，表单请求体按 form tag 分析字段名。
+func isFormContentType(contentType string) bool {
+	switch contentType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		return true
+	default:
+		return false
+	}
+}
+
+func buildExamples(examples []ResponseExample) map[string]*Example {
+	if len(examples) == 0 {
+		return nil
+	}
+	out := make(map[string]*Example, len(examples))
+	for i, ex := range examples {
+		name := ex.Name
+		if name == "" {
+			name = "example" + strconv.Itoa(i+1)
+		}
+		out[name] = &Example{Summary: ex.Summary, Value: ex.Value}
+	}
+	return out
+}
+
 func (b *Builder) buildParameter(spec ParameterSpec) *Parameter {
 	return &Parameter{
 		Name:        spec.Name,
@@ -338,6 +485,25 @@ func (b *Builder) buildSchema(node *SchemaNode) *Schema {
 			out.Properties[name] = b.buildSchema(child)
 		}
 	}
+	out.OneOf = b.buildSchemas(node.OneOf)
+	out.AnyOf = b.buildSchemas(node.AnyOf)
+	if node.Discriminator != nil {
+		out.Discriminator = &Discriminator{
+			PropertyName: node.Discriminator.PropertyName,
+			Mapping:      cloneStringMap(node.Discriminator.Mapping),
+		}
+	}
+	return out
+}
+
+func (b *Builder) buildSchemas(nodes []*SchemaNode) []*Schema {
+	if len(nodes) == 0 {
+		return nil
+	}
+	out := make([]*Schema, len(nodes))
+	for i, node := range nodes {
+		out[i] = b.buildSchema(node)
+	}
 	return out
 }
 
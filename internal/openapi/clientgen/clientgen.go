@@ -0,0 +1,529 @@
+// Package clientgen 根据 OpenAPI 文档生成类型化的 Go 与 TypeScript 客户端。
+//
+// 生成的客户端理解 qi 的统一响应包装：成功时解出 data，业务码非成功值时返回错误
+// （Go 为 *errors.Error，TypeScript 为 ApiError），与服务端 Context.Fail 的输出对应。
+package clientgen
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+// Envelope 描述统一响应包装的组件名和字段名。
+type Envelope struct {
+	Component    string // 组件名，如 "Response"
+	CodeField    string // 业务码字段，如 "code"
+	MessageField string // 消息字段，如 "message"
+	DataField    string // 数据字段，如 "data"
+	TraceIDField string // 追踪 ID 字段，如 "trace_id"，可为空
+	SuccessCode  int    // 成功业务码，通常为 0
+}
+
+// Config 生成配置。
+type Config struct {
+	Package  string    // Go 包名，默认 "client"
+	Envelope *Envelope // 为 nil 时所有响应按原始 JSON 解码
+
+	// Names 方法名提示，key 为 "GET /users/{id}"，通常取自路由的 handler 函数名。
+	// 优先级：operationId > Names > 由方法和路径推导。
+	Names map[string]string
+}
+
+// param 是一个路径、查询或请求头参数。
+type param struct {
+	Name     string
+	In       string
+	Required bool
+	Schema   *openapi.Schema
+}
+
+// operation 是生成器使用的操作模型。
+type operation struct {
+	Name        string // 导出形式的方法名，如 CreateUser
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Deprecated  bool
+
+	PathParams []param
+	Params     []param // 查询参数和请求头
+
+	Body            *openapi.Schema
+	BodyContentType string
+
+	Result    *openapi.Schema // 业务数据 schema，nil 表示无返回数据
+	Enveloped bool            // 响应是否为统一包装
+}
+
+var methodOrder = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodHead, http.MethodOptions,
+}
+
+func operationOf(item *openapi.PathItem, method string) *openapi.OperationObject {
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodHead:
+		return item.Head
+	case http.MethodOptions:
+		return item.Options
+	}
+	return nil
+}
+
+// collectOperations 按路径和方法排序收集操作，方法名去重。
+func collectOperations(doc *openapi.Document, cfg Config) []*operation {
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	used := make(map[string]int)
+	var ops []*operation
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, method := range methodOrder {
+			obj := operationOf(item, method)
+			if obj == nil {
+				continue
+			}
+			op := &operation{
+				Method:      method,
+				Path:        path,
+				Summary:     obj.Summary,
+				Description: obj.Description,
+				Deprecated:  obj.Deprecated,
+			}
+			op.Name = operationName(obj.OperationID, cfg.Names[method+" "+path], method, path)
+			if n := used[op.Name]; n > 0 {
+				used[op.Name] = n + 1
+				op.Name += strconv.Itoa(n + 1)
+			} else {
+				used[op.Name] = 1
+			}
+
+			for _, p := range obj.Parameters {
+				if p == nil {
+					continue
+				}
+				pp := param{Name: p.Name, In: p.In, Required: p.Required, Schema: p.Schema}
+				switch p.In {
+				case "path":
+					// 忽略路径模板中不存在的路径参数，避免生成无法使用的方法参数
+					if strings.Contains(path, "{"+p.Name+"}") {
+						op.PathParams = append(op.PathParams, pp)
+					}
+				case "query", "header":
+					op.Params = append(op.Params, pp)
+				}
+			}
+			// 路径参数按出现顺序作为方法参数
+			sort.SliceStable(op.PathParams, func(i, j int) bool {
+				return strings.Index(path, "{"+op.PathParams[i].Name+"}") < strings.Index(path, "{"+op.PathParams[j].Name+"}")
+			})
+
+			if rb := obj.RequestBody; rb != nil {
+				op.BodyContentType, op.Body = pickContent(rb.Content)
+			}
+			op.Result, op.Enveloped = successResult(doc, obj.Responses, cfg.Envelope)
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// pickContent 优先选择 JSON 内容类型。
+func pickContent(content map[string]*openapi.MediaType) (string, *openapi.Schema) {
+	if media, ok := content["application/json"]; ok && media != nil {
+		return "application/json", media.Schema
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	for _, ct := range types {
+		if media := content[ct]; media != nil {
+			return ct, media.Schema
+		}
+	}
+	return "", nil
+}
+
+// successResult 取状态码最小的 2xx 响应，识别统一包装并解出 data schema。
+func successResult(doc *openapi.Document, responses map[string]*openapi.APIResponse, env *Envelope) (*openapi.Schema, bool) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, env != nil
+	}
+	sort.Strings(codes)
+	resp := responses[codes[0]]
+	if resp == nil {
+		return nil, false
+	}
+	_, schema := pickContent(resp.Content)
+	if schema == nil {
+		return nil, false
+	}
+	if env == nil {
+		return schema, false
+	}
+
+	envRef := componentPrefix + env.Component
+	if schema.Ref == envRef {
+		return nil, true
+	}
+	wrapped := schema
+	if schema.Ref != "" {
+		wrapped = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]
+	}
+	if wrapped != nil && len(wrapped.AllOf) == 2 && wrapped.AllOf[0].Ref == envRef {
+		if data := wrapped.AllOf[1].Properties[env.DataField]; data != nil {
+			return data, true
+		}
+		return nil, true
+	}
+	return schema, false
+}
+
+// isEnvelopeComponent 判断组件是否为统一包装本身或按业务类型生成的包装组件。
+func isEnvelopeComponent(name string, env *Envelope) bool {
+	return env != nil && (name == env.Component || strings.HasPrefix(name, env.Component+"_"))
+}
+
+// operationName 生成导出形式的方法名。
+func operationName(operationID, hint, method, path string) string {
+	if name := exportedIdent(operationID); name != "" {
+		return name
+	}
+	if hint != "" {
+		// handler 名形如 "user.createUser"，取最后一段；方法值带 "-fm" 后缀，匿名函数（func1）不可用
+		hint = strings.TrimSuffix(hint, "-fm")
+		if idx := strings.LastIndex(hint, "."); idx >= 0 {
+			hint = hint[idx+1:]
+		}
+		if !isAnonymousFuncName(hint) {
+			if name := exportedIdent(hint); name != "" {
+				return name
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(exportedIdent(strings.ToLower(method)))
+	for _, seg := range strings.Split(path, "/") {
+		switch {
+		case seg == "":
+		case strings.HasPrefix(seg, "{"):
+			b.WriteString("By" + exportedIdent(strings.Trim(seg, "{}")))
+		default:
+			b.WriteString(exportedIdent(seg))
+		}
+	}
+	return b.String()
+}
+
+func isAnonymousFuncName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "func")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(rest)
+	return err == nil
+}
+
+// commonInitialisms 生成 Go 标识符时保持全大写的缩写。
+var commonInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "API": true, "HTTP": true,
+	"IP": true, "UUID": true, "JSON": true, "SQL": true,
+}
+
+// exportedIdent 将 user_id、user-id、userId 等转换为 UserID 形式的导出标识符。
+func exportedIdent(v string) string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	runes := []rune(v)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		rs := []rune(strings.ToLower(w))
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	out := b.String()
+	if out != "" && unicode.IsDigit([]rune(out)[0]) {
+		out = "X" + out
+	}
+	return out
+}
+
+// lowerIdent 将导出标识符转换为 lowerCamel 形式。
+func lowerIdent(v string) string {
+	if v == "" {
+		return v
+	}
+	runes := []rune(v)
+	// 开头的连续大写缩写整体小写，如 ID → id、URLPath → urlPath
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	switch {
+	case n == 0:
+	case n == 1 || n == len(runes):
+		for i := 0; i < n; i++ {
+			runes[i] = unicode.ToLower(runes[i])
+		}
+	default:
+		for i := 0; i < n-1; i++ {
+			runes[i] = unicode.ToLower(runes[i])
+		}
+	}
+	return string(runes)
+}
+
+// modeSuffixes 是分析器按模式追加的组件名后缀。
+var modeSuffixes = []string{".Body", ".Response", ".Query", ".Path", ".Header", ".Cookie", ".Form"}
+
+// typeNames 为组件分配简短且唯一的类型名：默认取 Go 类型名，跨包重名时加包名，
+// 同一类型在不同模式下 schema 不同时加模式后缀。
+func typeNames(doc *openapi.Document, env *Envelope) map[string]string {
+	type entry struct {
+		component, pkg, short, mode string
+	}
+	var entries []entry
+	for name := range doc.Components.Schemas {
+		if isEnvelopeComponent(name, env) {
+			continue
+		}
+		base, mode := name, ""
+		for _, suffix := range modeSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				base, mode = strings.TrimSuffix(name, suffix), strings.TrimPrefix(suffix, ".")
+				break
+			}
+		}
+		pkg, short := "", base
+		if idx := strings.LastIndex(base, "."); idx >= 0 {
+			pkg, short = base[:idx], base[idx+1:]
+			if j := strings.LastIndex(pkg, "."); j >= 0 {
+				pkg = pkg[j+1:]
+			}
+		}
+		entries = append(entries, entry{component: name, pkg: pkg, short: exportedIdent(short), mode: mode})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].component < entries[j].component })
+
+	pkgsByShort := make(map[string]map[string]bool)
+	for _, e := range entries {
+		if pkgsByShort[e.short] == nil {
+			pkgsByShort[e.short] = make(map[string]bool)
+		}
+		pkgsByShort[e.short][e.pkg] = true
+	}
+
+	out := make(map[string]string, len(entries))
+	byName := make(map[string][]entry)
+	for _, e := range entries {
+		name := e.short
+		if len(pkgsByShort[e.short]) > 1 {
+			name = exportedIdent(e.pkg) + name
+		}
+		byName[name] = append(byName[name], e)
+	}
+	for name, group := range byName {
+		components := make([]string, len(group))
+		for i, e := range group {
+			components[i] = e.component
+		}
+		if sameSchemas(doc, components) {
+			for _, e := range group {
+				out[e.component] = name
+			}
+			continue
+		}
+		for _, e := range group {
+			out[e.component] = name + e.mode
+		}
+	}
+	return out
+}
+
+// sameSchemas 判断同一类型在各模式下的 schema 是否一致（忽略引用的模式后缀）。
+func sameSchemas(doc *openapi.Document, components []string) bool {
+	norm := func(component string) string {
+		s := schemaFingerprint(doc.Components.Schemas[component])
+		for _, suffix := range modeSuffixes {
+			s = strings.ReplaceAll(s, suffix, "")
+		}
+		return s
+	}
+	want := norm(components[0])
+	for _, component := range components[1:] {
+		if norm(component) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaFingerprint 返回用于比较的 schema 结构描述。
+func schemaFingerprint(s *openapi.Schema) string {
+	if s == nil {
+		return ""
+	}
+	var b strings.Builder
+	writeFingerprint(&b, s)
+	return b.String()
+}
+
+func writeFingerprint(b *strings.Builder, s *openapi.Schema) {
+	if s == nil {
+		b.WriteString("nil")
+		return
+	}
+	fmt.Fprintf(b, "{%s|%s|%v|%s|%v|%v|", s.Ref, s.Type, s.Types, s.Format, s.Nullable, s.Required)
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ":")
+		writeFingerprint(b, s.Properties[name])
+	}
+	for _, list := range [][]*openapi.Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, child := range list {
+			writeFingerprint(b, child)
+		}
+	}
+	writeFingerprint(b, s.Items)
+	writeFingerprint(b, s.AdditionalProperties)
+	b.WriteString("}")
+}
+
+// isNullable 判断 schema 是否允许 null（3.0 nullable、3.1 type 数组或 anyOf[X, null]）。
+func isNullable(s *openapi.Schema) bool {
+	if s == nil {
+		return false
+	}
+	if s.Nullable {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == "null" {
+			return true
+		}
+	}
+	for _, member := range s.AllOf {
+		if member.Nullable {
+			return true
+		}
+	}
+	for _, member := range s.AnyOf {
+		if member.Type == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryType 返回 schema 的非 null 类型。
+func primaryType(s *openapi.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+// unwrapSchema 将 allOf[$ref, meta]、anyOf[X, null] 等包装形式还原为被包装的 schema。
+func unwrapSchema(s *openapi.Schema) *openapi.Schema {
+	if s == nil {
+		return nil
+	}
+	if s.Ref == "" && len(s.AllOf) >= 1 && s.AllOf[0].Ref != "" && len(s.Properties) == 0 {
+		return s.AllOf[0]
+	}
+	if len(s.AnyOf) == 2 {
+		for i, member := range s.AnyOf {
+			if member.Type == "null" {
+				return s.AnyOf[1-i]
+			}
+		}
+	}
+	return s
+}
+
+// sortedKeys 返回有序属性名。
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
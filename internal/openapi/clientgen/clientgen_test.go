@@ -0,0 +1,152 @@
+package clientgen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+type envelope struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+	TraceID string `json:"trace_id"`
+}
+
+type userPath struct {
+	ID int64 `uri:"id"`
+}
+
+type getUserQuery struct {
+	Fields string `form:"fields"`
+}
+
+type tenantHeader struct {
+	Tenant string `header:"X-Tenant-Id" binding:"required"`
+}
+
+type createUserReq struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email"`
+}
+
+type user struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Nickname *string  `json:"nickname"`
+	Tags     []string `json:"tags"`
+	Status   string   `json:"status" binding:"oneof=active disabled"`
+}
+
+func buildDoc(t *testing.T) *openapi.Document {
+	t.Helper()
+	m := openapi.New(openapi.WithResponseEnvelope("Response", envelope{}, "data"))
+	m.MustAddOperations(
+		openapi.Operation{
+			Method:    "GET",
+			Path:      "/users/{id}",
+			Summary:   "获取用户",
+			Request:   &openapi.Request{PathParams: userPath{}, QueryParams: getUserQuery{}, Headers: tenantHeader{}},
+			Responses: []openapi.Response{{Status: 200, Body: user{}}},
+		},
+		openapi.Operation{
+			Method:    "POST",
+			Path:      "/users",
+			Request:   &openapi.Request{Body: createUserReq{}, BodyRequired: true},
+			Responses: []openapi.Response{{Status: 200, Body: user{}}},
+		},
+		openapi.Operation{Method: "DELETE", Path: "/users/{id}", Request: &openapi.Request{PathParams: userPath{}}},
+	)
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return doc
+}
+
+func testConfig() Config {
+	return Config{
+		Package: "userapi",
+		Envelope: &Envelope{
+			Component:    "Response",
+			CodeField:    "code",
+			MessageField: "message",
+			DataField:    "data",
+			TraceIDField: "trace_id",
+		},
+		Names: map[string]string{"POST /users": "github.com/me/app/handler.CreateUser"},
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := GenerateGo(buildDoc(t), testConfig())
+	if err != nil {
+		t.Fatalf("GenerateGo: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "client.go", src, 0); err != nil {
+		t.Fatalf("generated Go does not parse: %v\n%s", err, src)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"package userapi",
+		"type User struct",
+		"Nickname *string",
+		"type GetUsersByIDParams struct",
+		"func (c *Client) GetUsersByID(ctx context.Context, id int64, params *GetUsersByIDParams) (*User, error)",
+		"func (c *Client) CreateUser(ctx context.Context, body *CreateUserReq) (*User, error)",
+		"func (c *Client) DeleteUsersByID(ctx context.Context, id int64) error",
+		"errors.NewWithStatus(",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated Go missing %q\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "type Response struct") {
+		t.Error("envelope component should not be generated as a type")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	src, err := GenerateTypeScript(buildDoc(t), testConfig())
+	if err != nil {
+		t.Fatalf("GenerateTypeScript: %v", err)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"export interface User {",
+		"nickname?: string | null;",
+		`status?: "active" | "disabled";`,
+		`"X-Tenant-Id": string;`,
+		"async getUsersByID(id: number, params: GetUsersByIDParams, init?: RequestInit): Promise<User>",
+		"async createUser(body: CreateUserReq, init?: RequestInit): Promise<User>",
+		"async deleteUsersByID(id: number, init?: RequestInit): Promise<void>",
+		"export class ApiError extends Error",
+		"const SUCCESS_CODE = 0;",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated TypeScript missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		id, hint, method, path string
+		want                   string
+	}{
+		{"listAccounts", "", "GET", "/users", "ListAccounts"},
+		{"", "github.com/me/app/handler.(*UserHandler).Get-fm", "GET", "/users/{id}", "Get"},
+		{"", "main.main.func1", "GET", "/users/{id}", "GetUsersByID"},
+		{"", "", "POST", "/api/v1/orders/{order_id}/items", "PostAPIV1OrdersByOrderIDItems"},
+	}
+	for _, tt := range tests {
+		if got := operationName(tt.id, tt.hint, tt.method, tt.path); got != tt.want {
+			t.Errorf("operationName(%q, %q, %q, %q) = %q, want %q", tt.id, tt.hint, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+// GenerateGo 生成 Go 客户端源码（单文件）。
+func GenerateGo(doc *openapi.Document, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "client"
+	}
+	g := &goGen{doc: doc, cfg: cfg, names: typeNames(doc, cfg.Envelope)}
+
+	var body bytes.Buffer
+	g.w = &body
+	g.writeTypes()
+	for _, op := range collectOperations(doc, cfg) {
+		g.writeOperation(op)
+	}
+
+	var out bytes.Buffer
+	if err := goRuntimeTemplate.Execute(&out, map[string]any{
+		"Package":   cfg.Package,
+		"Title":     doc.Info.Title,
+		"Version":   doc.Info.Version,
+		"Envelope":  cfg.Envelope,
+		"UsesTime":  g.usesTime,
+		"UsesBytes": g.usesBytes,
+	}); err != nil {
+		return nil, err
+	}
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("clientgen: format go client: %w", err)
+	}
+	return src, nil
+}
+
+type goGen struct {
+	doc       *openapi.Document
+	cfg       Config
+	names     map[string]string
+	w         *bytes.Buffer
+	usesTime  bool
+	usesBytes bool
+}
+
+func (g *goGen) printf(format string, args ...any) {
+	fmt.Fprintf(g.w, format, args...)
+}
+
+// writeTypes 按类型名输出组件类型，不同模式下一致的组件只输出一次。
+func (g *goGen) writeTypes() {
+	byName := make(map[string]string, len(g.names))
+	for component, name := range g.names {
+		if existing, ok := byName[name]; !ok || component < existing {
+			byName[name] = component
+		}
+	}
+	for _, name := range sortedKeys(byName) {
+		schema := g.doc.Components.Schemas[byName[name]]
+		if schema == nil {
+			continue
+		}
+		g.writeComment("", name, schema.Description)
+		switch {
+		case len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+			g.printf("//\n// 多态类型，可能的取值：%s。按判别属性自行解码。\n", strings.Join(g.variantNames(schema), "、"))
+			g.printf("type %s = json.RawMessage\n\n", name)
+		case primaryType(schema) == "object" && schema.AdditionalProperties == nil:
+			g.printf("type %s %s\n\n", name, g.structType(schema))
+		default:
+			g.printf("type %s %s\n\n", name, g.goType(schema))
+		}
+	}
+}
+
+func (g *goGen) variantNames(s *openapi.Schema) []string {
+	var out []string
+	for _, v := range append(append([]*openapi.Schema(nil), s.OneOf...), s.AnyOf...) {
+		out = append(out, g.goType(v))
+	}
+	return out
+}
+
+func (g *goGen) writeComment(indent, name, desc string) {
+	if desc == "" {
+		return
+	}
+	for i, line := range strings.Split(strings.TrimSpace(desc), "\n") {
+		if i == 0 && name != "" {
+			g.printf("%s// %s %s\n", indent, name, line)
+			continue
+		}
+		g.printf("%s// %s\n", indent, line)
+	}
+}
+
+// structType 生成结构体类型，字段按 JSON 名排序。
+func (g *goGen) structType(s *openapi.Schema) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	used := make(map[string]bool)
+	for _, prop := range sortedKeys(s.Properties) {
+		child := s.Properties[prop]
+		field := exportedIdent(prop)
+		if field == "" {
+			field = "Field"
+		}
+		for used[field] {
+			field += "_"
+		}
+		used[field] = true
+
+		required := contains(s.Required, prop)
+		if desc := unwrapDescription(child); desc != "" {
+			b.WriteString("// " + strings.ReplaceAll(strings.TrimSpace(desc), "\n", " ") + "\n")
+		}
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%s`\n", field, g.goType(child), strconv.Quote(tag))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func unwrapDescription(s *openapi.Schema) string {
+	if s == nil {
+		return ""
+	}
+	if s.Description != "" {
+		return s.Description
+	}
+	for _, member := range s.AllOf {
+		if member.Description != "" {
+			return member.Description
+		}
+	}
+	return ""
+}
+
+// goType 返回 schema 对应的 Go 类型表达式，可空的结构体和标量为指针。
+func (g *goGen) goType(s *openapi.Schema) string {
+	if s == nil {
+		return "any"
+	}
+	nullable := isNullable(s)
+	inner := unwrapSchema(s)
+	typ, pointerable := g.baseGoType(inner)
+	if nullable && pointerable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// baseGoType 返回不含可空性的 Go 类型，以及可空时是否需要指针。
+func (g *goGen) baseGoType(s *openapi.Schema) (string, bool) {
+	if s.Ref != "" {
+		component := strings.TrimPrefix(s.Ref, componentPrefix)
+		name, ok := g.names[component]
+		if !ok {
+			return "json.RawMessage", false
+		}
+		target := g.doc.Components.Schemas[component]
+		if target != nil && (len(target.OneOf) > 0 || len(target.AnyOf) > 0) {
+			return name, false
+		}
+		return name, true
+	}
+	if len(s.OneOf) > 0 || len(nonNull(s.AnyOf)) > 1 {
+		return "json.RawMessage", false
+	}
+
+	switch primaryType(s) {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.usesTime = true
+			return "time.Time", true
+		case "byte", "binary":
+			return "[]byte", false
+		}
+		return "string", true
+	case "integer":
+		if s.Format == "int64" {
+			return "int64", true
+		}
+		return "int", true
+	case "number":
+		if s.Format == "float" {
+			return "float32", true
+		}
+		return "float64", true
+	case "boolean":
+		return "bool", true
+	case "array":
+		return "[]" + g.goType(s.Items), false
+	case "object":
+		if len(s.Properties) > 0 {
+			return g.structType(s), true
+		}
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties), false
+		}
+		return "map[string]any", false
+	}
+	if len(s.Properties) > 0 {
+		return g.structType(s), true
+	}
+	return "any", false
+}
+
+func nonNull(schemas []*openapi.Schema) []*openapi.Schema {
+	var out []*openapi.Schema
+	for _, s := range schemas {
+		if s.Type != "null" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// isStructType 判断类型表达式是否为结构体（返回值使用指针）。
+func (g *goGen) isStructType(s *openapi.Schema) bool {
+	inner := unwrapSchema(s)
+	if inner.Ref != "" {
+		target := g.doc.Components.Schemas[strings.TrimPrefix(inner.Ref, componentPrefix)]
+		return target != nil && primaryType(target) == "object" && target.AdditionalProperties == nil &&
+			len(target.OneOf) == 0 && len(target.AnyOf) == 0
+	}
+	return primaryType(inner) == "object" && len(inner.Properties) > 0
+}
+
+func (g *goGen) writeOperation(op *operation) {
+	isJSONBody := op.Body != nil && (op.BodyContentType == "" || strings.Contains(op.BodyContentType, "json"))
+
+	// 查询参数和请求头结构体
+	paramsType := ""
+	fields := paramFieldNames(op.Params)
+	if len(op.Params) > 0 {
+		paramsType = op.Name + "Params"
+		g.printf("// %s 是 %s 的查询参数和请求头。\n", paramsType, op.Name)
+		g.printf("type %s struct {\n", paramsType)
+		for i, p := range op.Params {
+			typ := g.goType(p.Schema)
+			if !p.Required && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "*") && !strings.HasPrefix(typ, "map[") {
+				typ = "*" + typ
+			}
+			if desc := unwrapDescription(p.Schema); desc != "" {
+				g.printf("// %s\n", strings.ReplaceAll(desc, "\n", " "))
+			}
+			g.printf("%s %s // %s: %s\n", fields[i], typ, p.In, p.Name)
+		}
+		g.printf("}\n\n")
+	}
+
+	// 方法签名
+	args := []string{"ctx context.Context"}
+	for _, p := range op.PathParams {
+		args = append(args, goArgName(p.Name)+" "+strings.TrimPrefix(g.goType(p.Schema), "*"))
+	}
+	if paramsType != "" {
+		args = append(args, "params *"+paramsType)
+	}
+	switch {
+	case isJSONBody:
+		bodyType := g.goType(op.Body)
+		if g.isStructType(op.Body) && !strings.HasPrefix(bodyType, "*") {
+			bodyType = "*" + bodyType
+		}
+		args = append(args, "body "+bodyType)
+	case op.Body != nil:
+		args = append(args, "body io.Reader", "contentType string")
+	}
+
+	resultType := ""
+	resultPtr := false
+	if op.Result != nil {
+		resultType = g.goType(op.Result)
+		if g.isStructType(op.Result) && !strings.HasPrefix(resultType, "*") {
+			resultPtr = true
+		}
+		resultType = strings.TrimPrefix(resultType, "*")
+	}
+
+	g.printf("// %s 调用 %s %s。\n", op.Name, op.Method, op.Path)
+	if op.Summary != "" || op.Description != "" {
+		g.printf("//\n")
+		g.writeComment("", "", strings.TrimSpace(op.Summary+"\n"+op.Description))
+	}
+	if op.Deprecated {
+		g.printf("//\n// Deprecated: 接口已弃用。\n")
+	}
+	returns := "error"
+	if resultType != "" {
+		if resultPtr {
+			returns = "(*" + resultType + ", error)"
+		} else {
+			returns = "(" + resultType + ", error)"
+		}
+	}
+	g.printf("func (c *Client) %s(%s) %s {\n", op.Name, strings.Join(args, ", "), returns)
+
+	// 路径
+	g.printf("path := %s\n", g.pathExpr(op))
+
+	// 查询参数与请求头
+	query, header := "nil", "nil"
+	if paramsType != "" {
+		hasQuery, hasHeader := false, false
+		for _, p := range op.Params {
+			if p.In == "query" {
+				hasQuery = true
+			} else {
+				hasHeader = true
+			}
+		}
+		if hasQuery {
+			g.printf("query := url.Values{}\n")
+			query = "query"
+		}
+		if hasHeader {
+			g.printf("header := http.Header{}\n")
+			header = "header"
+		}
+		g.printf("if params != nil {\n")
+		for i, p := range op.Params {
+			target, method := "query", "Set"
+			if p.In == "header" {
+				target = "header"
+			}
+			field := "params." + fields[i]
+			typ := g.goType(p.Schema)
+			switch {
+			case strings.HasPrefix(typ, "[]") && typ != "[]byte":
+				g.printf("for _, v := range %s {\n%s.Add(%q, formatValue(v))\n}\n", field, target, p.Name)
+			case !p.Required || strings.HasPrefix(typ, "*"):
+				g.printf("if %s != nil {\n%s.%s(%q, formatValue(*%s))\n}\n", field, target, method, p.Name, field)
+			default:
+				g.printf("%s.%s(%q, formatValue(%s))\n", target, method, p.Name, field)
+			}
+		}
+		g.printf("}\n")
+	}
+
+	// 请求体
+	reader, contentType := "nil", `""`
+	switch {
+	case isJSONBody:
+		g.printf("data, err := json.Marshal(body)\nif err != nil {\n")
+		g.writeErrorReturn(resultType, resultPtr)
+		g.printf("}\n")
+		g.usesBytes = true
+		reader, contentType = "bytes.NewReader(data)", strconv.Quote("application/json")
+	case op.Body != nil:
+		reader, contentType = "body", "contentType"
+	}
+
+	call := fmt.Sprintf("c.do(ctx, %q, path, %s, %s, %s, %s, %v, %%s)", op.Method, query, header, reader, contentType, op.Enveloped)
+	switch {
+	case resultType == "":
+		g.printf("return "+call+"\n", "nil")
+	case resultPtr:
+		g.printf("var out %s\nif err := "+call+"; err != nil {\nreturn nil, err\n}\nreturn &out, nil\n", resultType, "&out")
+	default:
+		g.printf("var out %s\nerr := "+call+"\nreturn out, err\n", resultType, "&out")
+	}
+	g.printf("}\n\n")
+}
+
+func (g *goGen) writeErrorReturn(resultType string, resultPtr bool) {
+	switch {
+	case resultType == "":
+		g.printf("return err\n")
+	case resultPtr:
+		g.printf("return nil, err\n")
+	default:
+		g.printf("var zero %s\nreturn zero, err\n", resultType)
+	}
+}
+
+// goReserved 是生成方法中已使用的局部变量名和 Go 关键字，路径参数同名时追加 Param 后缀。
+// paramFieldNames 返回参数结构体的字段名，查询参数与请求头同名时追加位置后缀。
+func paramFieldNames(params []param) []string {
+	names := make([]string, len(params))
+	seen := make(map[string]int, len(params))
+	for i, p := range params {
+		names[i] = exportedIdent(p.Name)
+		seen[names[i]]++
+	}
+	for i, p := range params {
+		if seen[names[i]] > 1 {
+			names[i] += exportedIdent(p.In)
+		}
+	}
+	return names
+}
+
+var goReserved = map[string]bool{
+	"c": true, "ctx": true, "path": true, "query": true, "header": true, "params": true,
+	"body": true, "contentType": true, "data": true, "out": true, "err": true, "zero": true,
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "url": true, "http": true, "json": true, "errors": true,
+}
+
+func goArgName(name string) string {
+	arg := lowerIdent(exportedIdent(name))
+	if arg == "" || goReserved[arg] {
+		arg += "Param"
+	}
+	return arg
+}
+
+// pathExpr 生成拼接路径参数的表达式。
+func (g *goGen) pathExpr(op *operation) string {
+	if len(op.PathParams) == 0 {
+		return strconv.Quote(op.Path)
+	}
+	var parts []string
+	rest := op.Path
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		name := rest[start+1 : end]
+		parts = append(parts, "url.PathEscape(formatValue("+goArgName(name)+"))")
+		rest = rest[end+1:]
+	}
+	if rest != "" {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+var goRuntimeTemplate = template.Must(template.New("go").Parse(`// Code generated by qi clientgen. DO NOT EDIT.
+
+// Package {{.Package}} 是 {{.Title}}{{if .Version}} {{.Version}}{{end}} 的类型化客户端。
+package {{.Package}}
+
+import (
+{{- if .UsesBytes}}
+	"bytes"
+{{- end}}
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+
+	"github.com/tokmz/qi/pkg/errors"
+)
+
+// Client 是 API 客户端，可被多个 goroutine 并发使用。
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	editors    []RequestEditor
+}
+
+// RequestEditor 在请求发送前修改请求，如设置鉴权头、透传追踪信息。
+type RequestEditor func(ctx context.Context, req *http.Request) error
+
+// Option 是客户端选项。
+type Option func(*Client)
+
+// WithHTTPClient 设置底层 http.Client，默认 http.DefaultClient。
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithRequestEditor 追加请求修改器。
+func WithRequestEditor(fn RequestEditor) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.editors = append(c.editors, fn)
+		}
+	}
+}
+
+// WithBearerToken 为每个请求设置 Authorization: Bearer 头。
+func WithBearerToken(token string) Option {
+	return WithRequestEditor(func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// New 创建客户端，baseURL 如 "http://user-service:8080"。
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+{{- with .Envelope}}
+
+// envelope 是统一响应包装。
+type envelope struct {
+	Code    int             ` + "`json:\"{{.CodeField}}\"`" + `
+	Message string          ` + "`json:\"{{.MessageField}}\"`" + `
+	Data    json.RawMessage ` + "`json:\"{{.DataField}}\"`" + `
+}
+
+// successCode 是成功业务码，其余业务码转换为 *errors.Error。
+const successCode = {{.SuccessCode}}
+{{- end}}
+
+// do 发送请求并解码响应。enveloped 为 true 时解出统一响应中的数据，业务码非成功值时返回 *errors.Error；
+// HTTP 状态码 >= 400 且无法解析业务码时，以状态码作为错误码。
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader, contentType string, enveloped bool, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, edit := range c.editors {
+		if err := edit(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+{{- if .Envelope}}
+
+	if enveloped || resp.StatusCode >= http.StatusBadRequest {
+		var env envelope
+		if err := json.Unmarshal(data, &env); err == nil && env.Code != successCode {
+			return errors.NewWithStatus(env.Code, resp.StatusCode, env.Message)
+		} else if resp.StatusCode >= http.StatusBadRequest {
+			return statusError(resp.StatusCode, data)
+		} else if err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		if out == nil || len(env.Data) == 0 || string(env.Data) == "null" {
+			return nil
+		}
+		return json.Unmarshal(env.Data, out)
+	}
+{{- else}}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return statusError(resp.StatusCode, data)
+	}
+{{- end}}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func statusError(status int, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return errors.NewWithStatus(status, status, msg)
+}
+
+// formatValue 格式化路径、查询和请求头参数，实现 encoding.TextMarshaler 的类型（如 time.Time）使用其文本形式。
+func formatValue(v any) string {
+	if m, ok := v.(interface{ MarshalText() ([]byte, error) }); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+`))
@@ -0,0 +1,435 @@
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+// GenerateTypeScript 生成基于 fetch 的 TypeScript 客户端源码（单文件，无第三方依赖）。
+func GenerateTypeScript(doc *openapi.Document, cfg Config) ([]byte, error) {
+	g := &tsGen{doc: doc, cfg: cfg, names: typeNames(doc, cfg.Envelope)}
+
+	var types, methods bytes.Buffer
+	g.w = &types
+	g.writeTypes()
+	ops := collectOperations(doc, cfg)
+	for _, op := range ops {
+		g.writeParamsType(op)
+	}
+	g.w = &methods
+	for _, op := range ops {
+		g.writeMethod(op)
+	}
+
+	var out bytes.Buffer
+	if err := tsRuntimeTemplate.Execute(&out, map[string]any{
+		"Title":    doc.Info.Title,
+		"Version":  doc.Info.Version,
+		"Envelope": cfg.Envelope,
+		"Types":    types.String(),
+		"Methods":  strings.TrimRight(methods.String(), "\n"),
+	}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type tsGen struct {
+	doc   *openapi.Document
+	cfg   Config
+	names map[string]string
+	w     *bytes.Buffer
+}
+
+func (g *tsGen) printf(format string, args ...any) {
+	fmt.Fprintf(g.w, format, args...)
+}
+
+func (g *tsGen) writeTypes() {
+	byName := make(map[string]string, len(g.names))
+	for component, name := range g.names {
+		if existing, ok := byName[name]; !ok || component < existing {
+			byName[name] = component
+		}
+	}
+	for _, name := range sortedKeys(byName) {
+		schema := g.doc.Components.Schemas[byName[name]]
+		if schema == nil {
+			continue
+		}
+		g.writeDoc("", schema.Description)
+		if primaryType(schema) == "object" && len(schema.Properties) > 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 {
+			g.printf("export interface %s %s\n\n", name, g.objectType(schema, ""))
+			continue
+		}
+		g.printf("export type %s = %s;\n\n", name, g.tsType(schema, ""))
+	}
+}
+
+func (g *tsGen) writeDoc(indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		g.printf("%s/** %s */\n", indent, lines[0])
+		return
+	}
+	g.printf("%s/**\n", indent)
+	for _, line := range lines {
+		g.printf("%s * %s\n", indent, line)
+	}
+	g.printf("%s */\n", indent)
+}
+
+// objectType 生成对象类型字面量，indent 为外层缩进。
+func (g *tsGen) objectType(s *openapi.Schema, indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, prop := range sortedKeys(s.Properties) {
+		child := s.Properties[prop]
+		if desc := unwrapDescription(child); desc != "" {
+			fmt.Fprintf(&b, "%s  /** %s */\n", indent, strings.ReplaceAll(strings.TrimSpace(desc), "\n", " "))
+		}
+		optional := "?"
+		if contains(s.Required, prop) {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, tsPropertyKey(prop), optional, g.tsType(child, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// tsType 返回 schema 对应的 TypeScript 类型表达式。
+func (g *tsGen) tsType(s *openapi.Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	typ := g.baseTSType(unwrapSchema(s), indent)
+	if isNullable(s) && typ != "unknown" {
+		return typ + " | null"
+	}
+	return typ
+}
+
+func (g *tsGen) baseTSType(s *openapi.Schema, indent string) string {
+	if s.Ref != "" {
+		if name, ok := g.names[strings.TrimPrefix(s.Ref, componentPrefix)]; ok {
+			return name
+		}
+		return "unknown"
+	}
+	if variants := append(append([]*openapi.Schema(nil), s.OneOf...), nonNull(s.AnyOf)...); len(variants) > 0 {
+		parts := make([]string, len(variants))
+		for i, v := range variants {
+			parts[i] = g.tsType(v, indent)
+		}
+		return strings.Join(parts, " | ")
+	}
+	if len(s.Enum) > 0 {
+		parts := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			if v == nil {
+				continue
+			}
+			parts = append(parts, tsLiteral(v))
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, " | ")
+		}
+	}
+
+	switch primaryType(s) {
+	case "string":
+		if s.Format == "binary" || s.ContentMediaType != "" {
+			return "Blob"
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := g.tsType(s.Items, indent)
+		if strings.Contains(item, "|") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(s.Properties) > 0 {
+			return g.objectType(s, indent)
+		}
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + g.tsType(s.AdditionalProperties, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	if len(s.Properties) > 0 {
+		return g.objectType(s, indent)
+	}
+	return "unknown"
+}
+
+func tsLiteral(v any) string {
+	switch val := v.(type) {
+	case string:
+		return strconv.Quote(val)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+var tsIdentPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsPropertyKey(name string) string {
+	if tsIdentPattern.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// tsReserved 是 TypeScript 保留字和生成方法中的参数名。
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"params": true, "body": true, "init": true,
+}
+
+func tsArgName(name string) string {
+	arg := lowerIdent(exportedIdent(name))
+	if arg == "" || tsReserved[arg] {
+		arg += "Param"
+	}
+	return arg
+}
+
+func (g *tsGen) writeParamsType(op *operation) {
+	if len(op.Params) == 0 {
+		return
+	}
+	g.printf("/** %s %s 的查询参数和请求头。 */\n", op.Method, op.Path)
+	g.printf("export interface %sParams {\n", op.Name)
+	for _, p := range op.Params {
+		if desc := unwrapDescription(p.Schema); desc != "" {
+			g.printf("  /** %s */\n", strings.ReplaceAll(desc, "\n", " "))
+		}
+		optional := "?"
+		if p.Required {
+			optional = ""
+		}
+		g.printf("  %s%s: %s;\n", tsPropertyKey(p.Name), optional, g.tsType(p.Schema, "  "))
+	}
+	g.printf("}\n\n")
+}
+
+func (g *tsGen) writeMethod(op *operation) {
+	isJSONBody := op.Body != nil && (op.BodyContentType == "" || strings.Contains(op.BodyContentType, "json"))
+
+	var args []string
+	for _, p := range op.PathParams {
+		args = append(args, tsArgName(p.Name)+": "+g.tsType(p.Schema, "    "))
+	}
+	required := false
+	for _, p := range op.Params {
+		required = required || p.Required
+	}
+	// 有必填参数时 params 必填，且排在请求体之前
+	if len(op.Params) > 0 {
+		if required {
+			args = append(args, "params: "+op.Name+"Params")
+		} else if op.Body == nil {
+			args = append(args, "params?: "+op.Name+"Params")
+		} else {
+			args = append(args, "params: "+op.Name+"Params | undefined")
+		}
+	}
+	switch {
+	case isJSONBody:
+		args = append(args, "body: "+g.tsType(op.Body, "    "))
+	case op.Body != nil:
+		args = append(args, "body: FormData | URLSearchParams")
+	}
+	args = append(args, "init?: RequestInit")
+
+	result := "void"
+	if op.Result != nil {
+		result = g.tsType(op.Result, "    ")
+	}
+
+	doc := op.Method + " " + op.Path
+	if op.Summary != "" {
+		doc += "\n\n" + op.Summary
+	}
+	if op.Description != "" {
+		doc += "\n\n" + op.Description
+	}
+	if op.Deprecated {
+		doc += "\n\n@deprecated"
+	}
+	g.writeDoc("  ", doc)
+	g.printf("  async %s(%s): Promise<%s> {\n", lowerIdent(op.Name), strings.Join(args, ", "), result)
+	g.printf("    return this.request<%s>('%s', %s, {\n", result, op.Method, g.pathTemplate(op))
+
+	var query, headers []string
+	for _, p := range op.Params {
+		entry := fmt.Sprintf("%s: params?.%s", tsPropertyKey(p.Name), tsAccess(p.Name))
+		if p.In == "header" {
+			headers = append(headers, entry)
+		} else {
+			query = append(query, entry)
+		}
+	}
+	if len(query) > 0 {
+		g.printf("      query: { %s },\n", strings.Join(query, ", "))
+	}
+	if len(headers) > 0 {
+		g.printf("      headers: { %s },\n", strings.Join(headers, ", "))
+	}
+	switch {
+	case isJSONBody:
+		g.printf("      body,\n      json: true,\n")
+	case op.Body != nil:
+		g.printf("      body,\n")
+	}
+	g.printf("      enveloped: %v,\n", op.Enveloped)
+	g.printf("    }, init);\n  }\n\n")
+}
+
+func tsAccess(name string) string {
+	if tsIdentPattern.MatchString(name) {
+		return name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+func (g *tsGen) pathTemplate(op *operation) string {
+	if len(op.PathParams) == 0 {
+		return "'" + op.Path + "'"
+	}
+	path := op.Path
+	for _, p := range op.PathParams {
+		path = strings.ReplaceAll(path, "{"+p.Name+"}", "${encodeURIComponent(String("+tsArgName(p.Name)+"))}")
+	}
+	return "`" + path + "`"
+}
+
+var tsRuntimeTemplate = template.Must(template.New("ts").Parse(`// Code generated by qi clientgen. DO NOT EDIT.
+// {{.Title}}{{if .Version}} {{.Version}}{{end}}
+
+{{.Types}}/** 接口错误：code 为业务码（无法解析时为 HTTP 状态码），status 为 HTTP 状态码。 */
+export class ApiError extends Error {
+  readonly code: number;
+  readonly status: number;
+  readonly traceId?: string;
+
+  constructor(code: number, status: number, message: string, traceId?: string) {
+    super(message);
+    this.name = 'ApiError';
+    this.code = code;
+    this.status = status;
+    this.traceId = traceId;
+  }
+}
+
+export type HeadersProvider = Record<string, string> | (() => Record<string, string> | Promise<Record<string, string>>);
+
+export interface ClientOptions {
+  /** 自定义 fetch 实现，默认使用全局 fetch。 */
+  fetch?: typeof fetch;
+  /** 每个请求附加的请求头，如 Authorization。 */
+  headers?: HeadersProvider;
+}
+
+interface RequestOptions {
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  body?: unknown;
+  json?: boolean;
+  enveloped: boolean;
+}
+{{- with .Envelope}}
+
+const SUCCESS_CODE = {{.SuccessCode}};
+{{- end}}
+
+export class Client {
+  private readonly baseURL: string;
+  private readonly options: ClientOptions;
+
+  constructor(baseURL: string, options: ClientOptions = {}) {
+    this.baseURL = baseURL.replace(/\/+$/, '');
+    this.options = options;
+  }
+
+{{.Methods}}
+
+  private async request<T>(method: string, path: string, opts: RequestOptions, init?: RequestInit): Promise<T> {
+    const qs = new URLSearchParams();
+    for (const [key, value] of Object.entries(opts.query ?? {})) {
+      if (value === undefined || value === null) continue;
+      for (const item of Array.isArray(value) ? value : [value]) {
+        qs.append(key, item instanceof Date ? item.toISOString() : String(item));
+      }
+    }
+    const provided = typeof this.options.headers === 'function' ? await this.options.headers() : this.options.headers;
+    const headers: Record<string, string> = { Accept: 'application/json', ...provided };
+    for (const [key, value] of Object.entries(opts.headers ?? {})) {
+      if (value !== undefined && value !== null) headers[key] = String(value);
+    }
+    let body: BodyInit | undefined;
+    if (opts.body !== undefined) {
+      if (opts.json) {
+        headers['Content-Type'] = 'application/json';
+        body = JSON.stringify(opts.body);
+      } else {
+        body = opts.body as BodyInit;
+      }
+    }
+
+    const query = qs.toString();
+    const doFetch = this.options.fetch ?? fetch;
+    const res = await doFetch(this.baseURL + path + (query ? '?' + query : ''), {
+      ...init,
+      method,
+      headers: { ...headers, ...(init?.headers as Record<string, string> | undefined) },
+      body,
+    });
+    const text = await res.text();
+    let payload: any;
+    try {
+      payload = text ? JSON.parse(text) : undefined;
+    } catch {
+      payload = undefined;
+    }
+{{- with .Envelope}}
+
+    if (opts.enveloped || !res.ok) {
+      const code = payload?.{{.CodeField}};
+      if (typeof code === 'number' && code !== SUCCESS_CODE) {
+        throw new ApiError(code, res.status, String(payload?.{{.MessageField}} ?? ''){{if .TraceIDField}}, payload?.{{.TraceIDField}}{{end}});
+      }
+      if (!res.ok) throw new ApiError(res.status, res.status, text || res.statusText);
+      return payload?.{{.DataField}} as T;
+    }
+{{- else}}
+
+    if (!res.ok) throw new ApiError(res.status, res.status, text || res.statusText);
+{{- end}}
+    return payload as T;
+  }
+}
+`))
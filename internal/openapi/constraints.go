@@ -4,8 +4,18 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+
+	qiregexp "github.com/tokmz/qi/utils/regexp"
 )
 
+// tagPatterns qi 内置校验 tag（见 qi.RegisterValidation）对应的正则，文档中输出为 pattern。
+var tagPatterns = map[string]string{
+	"phone":      qiregexp.PhoneCNPattern,
+	"idcard":     qiregexp.IDCardPattern,
+	"creditcode": qiregexp.CreditCodePattern,
+	"bankcard":   qiregexp.BankCardPattern,
+}
+
 type ConstraintSet struct {
 	Required         bool
 	Enum             []string
@@ -77,6 +87,8 @@ func parseConstraintTokens(out *ConstraintSet, typ reflect.Type, tag string) {
 			out.Format = "uuid"
 		case "datetime":
 			out.Format = "date-time"
+		case "phone", "idcard", "creditcode", "bankcard":
+			out.Pattern = tagPatterns[key]
 		default:
 			if out.Raw == nil {
 				out.Raw = make(map[string]string)
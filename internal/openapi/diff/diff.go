@@ -0,0 +1,334 @@
+// Package diff 比较两份 OpenAPI 文档，按对现有客户端的影响将变更分为 breaking 与 non-breaking。
+//
+// 判定规则遵循“请求放宽、响应收紧”均兼容的原则：
+//   - 请求侧：新增必填参数/属性、可选变必填、枚举收窄、类型变化、约束收紧为 breaking
+//   - 响应侧：删除属性、必填变可选、枚举扩充、可空化、类型变化为 breaking
+//   - 删除路径、操作、成功响应或请求内容类型，以及收紧安全要求为 breaking
+package diff
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+// Severity 变更的兼容性级别。
+type Severity string
+
+const (
+	SeverityBreaking    Severity = "breaking"
+	SeverityNonBreaking Severity = "non-breaking"
+)
+
+// Kind 变更类型。
+type Kind string
+
+const (
+	KindPathAdded         Kind = "path-added"
+	KindPathRemoved       Kind = "path-removed"
+	KindOperationAdded    Kind = "operation-added"
+	KindOperationRemoved  Kind = "operation-removed"
+	KindParamAdded        Kind = "param-added"
+	KindParamRemoved      Kind = "param-removed"
+	KindRequiredChanged   Kind = "required-changed"
+	KindBodyAdded         Kind = "body-added"
+	KindBodyRemoved       Kind = "body-removed"
+	KindMediaTypeAdded    Kind = "media-type-added"
+	KindMediaTypeRemoved  Kind = "media-type-removed"
+	KindResponseAdded     Kind = "response-added"
+	KindResponseRemoved   Kind = "response-removed"
+	KindPropertyAdded     Kind = "property-added"
+	KindPropertyRemoved   Kind = "property-removed"
+	KindTypeChanged       Kind = "type-changed"
+	KindNullableChanged   Kind = "nullable-changed"
+	KindEnumChanged       Kind = "enum-changed"
+	KindVariantChanged    Kind = "variant-changed"
+	KindConstraintChanged Kind = "constraint-changed"
+	KindSecurityChanged   Kind = "security-changed"
+)
+
+// Change 单条变更。
+type Change struct {
+	Kind     Kind     `json:"kind"`
+	Severity Severity `json:"severity"`
+	Method   string   `json:"method,omitempty"`
+	Path     string   `json:"path"`
+	Location string   `json:"location,omitempty"` // 如 query.page、body.user.name、response.200.data.id
+	Message  string   `json:"message"`
+}
+
+// Report 比较结果，Changes 按路径、方法和位置有序。
+type Report struct {
+	Breaking    int      `json:"breaking"`
+	NonBreaking int      `json:"nonBreaking"`
+	Changes     []Change `json:"changes"`
+}
+
+// HasBreaking 是否包含 breaking 变更。
+func (r *Report) HasBreaking() bool {
+	return r.Breaking > 0
+}
+
+// BreakingChanges 返回全部 breaking 变更。
+func (r *Report) BreakingChanges() []Change {
+	var out []Change
+	for _, c := range r.Changes {
+		if c.Severity == SeverityBreaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Compare 比较 base（如已提交的 spec）与 head（新构建的 spec）。
+func Compare(base, head *openapi.Document) *Report {
+	c := &comparer{
+		base:     base,
+		head:     head,
+		report:   &Report{Changes: []Change{}},
+		visiting: make(map[string]bool),
+	}
+	c.comparePaths()
+	return c.report
+}
+
+// direction 区分请求与响应，决定同一变化的兼容性。
+type direction int
+
+const (
+	dirRequest direction = iota
+	dirResponse
+)
+
+type comparer struct {
+	base, head *openapi.Document
+	report     *Report
+
+	method, path string
+	visiting     map[string]bool
+}
+
+func (c *comparer) add(kind Kind, breaking bool, location, format string, args ...any) {
+	sev := SeverityNonBreaking
+	if breaking {
+		sev = SeverityBreaking
+		c.report.Breaking++
+	} else {
+		c.report.NonBreaking++
+	}
+	c.report.Changes = append(c.report.Changes, Change{
+		Kind:     kind,
+		Severity: sev,
+		Method:   c.method,
+		Path:     c.path,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+var methods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete,
+	http.MethodPatch, http.MethodHead, http.MethodOptions,
+}
+
+func operationOf(item *openapi.PathItem, method string) *openapi.OperationObject {
+	if item == nil {
+		return nil
+	}
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodHead:
+		return item.Head
+	case http.MethodOptions:
+		return item.Options
+	}
+	return nil
+}
+
+func (c *comparer) comparePaths() {
+	for _, path := range unionKeys(c.base.Paths, c.head.Paths) {
+		c.path, c.method = path, ""
+		b, h := c.base.Paths[path], c.head.Paths[path]
+		switch {
+		case h == nil:
+			c.add(KindPathRemoved, true, "", "path removed")
+			continue
+		case b == nil:
+			c.add(KindPathAdded, false, "", "path added")
+			continue
+		}
+		for _, method := range methods {
+			c.method = method
+			bo, ho := operationOf(b, method), operationOf(h, method)
+			switch {
+			case bo == nil && ho == nil:
+			case ho == nil:
+				c.add(KindOperationRemoved, true, "", "operation removed")
+			case bo == nil:
+				c.add(KindOperationAdded, false, "", "operation added")
+			default:
+				c.compareOperation(bo, ho)
+			}
+		}
+	}
+}
+
+func (c *comparer) compareOperation(b, h *openapi.OperationObject) {
+	c.compareParameters(b.Parameters, h.Parameters)
+	c.compareRequestBody(b.RequestBody, h.RequestBody)
+	c.compareResponses(b.Responses, h.Responses)
+	c.compareSecurity(b.Security, h.Security)
+}
+
+func (c *comparer) compareParameters(base, head []*openapi.Parameter) {
+	index := func(params []*openapi.Parameter) map[string]*openapi.Parameter {
+		out := make(map[string]*openapi.Parameter, len(params))
+		for _, p := range params {
+			if p != nil {
+				out[p.In+"."+p.Name] = p
+			}
+		}
+		return out
+	}
+	bm, hm := index(base), index(head)
+	for _, key := range unionKeys(bm, hm) {
+		b, h := bm[key], hm[key]
+		switch {
+		case h == nil:
+			c.add(KindParamRemoved, false, key, "parameter removed")
+		case b == nil:
+			if h.Required {
+				c.add(KindParamAdded, true, key, "required parameter added")
+			} else {
+				c.add(KindParamAdded, false, key, "optional parameter added")
+			}
+		default:
+			if !b.Required && h.Required {
+				c.add(KindRequiredChanged, true, key, "parameter became required")
+			} else if b.Required && !h.Required {
+				c.add(KindRequiredChanged, false, key, "parameter became optional")
+			}
+			c.compareSchema(key, b.Schema, h.Schema, dirRequest)
+		}
+	}
+}
+
+func (c *comparer) compareRequestBody(b, h *openapi.RequestBody) {
+	switch {
+	case b == nil && h == nil:
+		return
+	case h == nil:
+		c.add(KindBodyRemoved, false, "body", "request body removed")
+		return
+	case b == nil:
+		if h.Required {
+			c.add(KindBodyAdded, true, "body", "required request body added")
+		} else {
+			c.add(KindBodyAdded, false, "body", "optional request body added")
+		}
+		return
+	}
+	if !b.Required && h.Required {
+		c.add(KindRequiredChanged, true, "body", "request body became required")
+	} else if b.Required && !h.Required {
+		c.add(KindRequiredChanged, false, "body", "request body became optional")
+	}
+	c.compareContent("body", b.Content, h.Content, dirRequest)
+}
+
+func (c *comparer) compareResponses(base, head map[string]*openapi.APIResponse) {
+	for _, status := range unionKeys(base, head) {
+		b, h := base[status], head[status]
+		loc := "response." + status
+		switch {
+		case h == nil:
+			// 删除成功响应会让依赖它的客户端失效，删除错误响应声明则不影响调用
+			c.add(KindResponseRemoved, strings.HasPrefix(status, "2"), loc, "response %s removed", status)
+		case b == nil:
+			c.add(KindResponseAdded, false, loc, "response %s added", status)
+		default:
+			c.compareContent(loc, b.Content, h.Content, dirResponse)
+		}
+	}
+}
+
+func (c *comparer) compareContent(loc string, base, head map[string]*openapi.MediaType, dir direction) {
+	for _, ct := range unionKeys(base, head) {
+		b, h := base[ct], head[ct]
+		switch {
+		case h == nil:
+			c.add(KindMediaTypeRemoved, true, loc, "media type %s removed", ct)
+		case b == nil:
+			c.add(KindMediaTypeAdded, false, loc, "media type %s added", ct)
+		default:
+			c.compareSchema(loc, b.Schema, h.Schema, dir)
+		}
+	}
+}
+
+// compareSecurity 安全要求是“任一满足”的备选列表：删除备选或从无到有为 breaking。
+func (c *comparer) compareSecurity(base, head []map[string][]string) {
+	bs, hs := securitySet(base), securitySet(head)
+	if len(bs) == 0 && len(hs) == 0 {
+		return
+	}
+	if len(bs) == 0 {
+		c.add(KindSecurityChanged, true, "security", "security requirement added")
+		return
+	}
+	if len(hs) == 0 {
+		c.add(KindSecurityChanged, false, "security", "security requirement removed")
+		return
+	}
+	for _, key := range unionKeys(bs, hs) {
+		_, inBase := bs[key]
+		_, inHead := hs[key]
+		switch {
+		case !inHead:
+			c.add(KindSecurityChanged, true, "security", "security alternative %s removed", key)
+		case !inBase:
+			c.add(KindSecurityChanged, false, "security", "security alternative %s added", key)
+		}
+	}
+}
+
+func securitySet(reqs []map[string][]string) map[string]struct{} {
+	out := make(map[string]struct{}, len(reqs))
+	for _, req := range reqs {
+		parts := make([]string, 0, len(req))
+		for name, scopes := range req {
+			scopes = append([]string(nil), scopes...)
+			sort.Strings(scopes)
+			parts = append(parts, name+"["+strings.Join(scopes, ",")+"]")
+		}
+		sort.Strings(parts)
+		out[strings.Join(parts, "+")] = struct{}{}
+	}
+	return out
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+type listReqV1 struct {
+	Page   int    `form:"page"`
+	Status string `form:"status" binding:"oneof=active disabled deleted"`
+}
+
+type listReqV2 struct {
+	Page   int    `form:"page"`
+	Status string `form:"status" binding:"oneof=active disabled"`
+	Tenant string `form:"tenant" binding:"required"`
+}
+
+type userV1 struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Age   *int     `json:"age"`
+	Tags  []string `json:"tags"`
+}
+
+type userV2 struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Age   *int     `json:"age"`
+	Tags  []string `json:"tags"`
+	Phone string   `json:"phone"`
+}
+
+type createReqV1 struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+type createReqV2 struct {
+	Name     string `json:"name" binding:"required,max=32"`
+	Nickname string `json:"nickname"`
+}
+
+func build(t *testing.T, version string, ops ...openapi.Operation) *openapi.Document {
+	t.Helper()
+	m := openapi.New(openapi.WithOpenAPIVersion(version))
+	m.MustAddOperations(ops...)
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return doc
+}
+
+func v1Ops() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:    "GET",
+			Path:      "/users",
+			Request:   &openapi.Request{QueryParams: listReqV1{}},
+			Responses: []openapi.Response{{Status: 200, Body: []userV1{}}},
+		},
+		{
+			Method:    "POST",
+			Path:      "/users",
+			Request:   &openapi.Request{Body: createReqV1{}, BodyRequired: true},
+			Responses: []openapi.Response{{Status: 200, Body: userV1{}}},
+		},
+		{Method: "DELETE", Path: "/users/{id}"},
+	}
+}
+
+func v2Ops() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:    "GET",
+			Path:      "/users",
+			Request:   &openapi.Request{QueryParams: listReqV2{}},
+			Responses: []openapi.Response{{Status: 200, Body: []userV2{}}},
+		},
+		{
+			Method:    "POST",
+			Path:      "/users",
+			Request:   &openapi.Request{Body: createReqV2{}, BodyRequired: true},
+			Responses: []openapi.Response{{Status: 200, Body: userV2{}}},
+		},
+		{Method: "GET", Path: "/health"},
+	}
+}
+
+func findChange(r *Report, kind Kind, method, location string) *Change {
+	for i := range r.Changes {
+		c := &r.Changes[i]
+		if c.Kind == kind && c.Method == method && c.Location == location {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestCompareIdentical(t *testing.T) {
+	report := Compare(build(t, openapi.Version30, v1Ops()...), build(t, openapi.Version30, v1Ops()...))
+	if len(report.Changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", report.Changes)
+	}
+}
+
+func TestCompareClassifiesChanges(t *testing.T) {
+	report := Compare(build(t, openapi.Version30, v1Ops()...), build(t, openapi.Version30, v2Ops()...))
+	if !report.HasBreaking() {
+		t.Fatal("expected breaking changes")
+	}
+
+	cases := []struct {
+		kind     Kind
+		method   string
+		location string
+		severity Severity
+	}{
+		{KindPathRemoved, "", "", SeverityBreaking},
+		{KindPathAdded, "", "", SeverityNonBreaking},
+		{KindParamAdded, "GET", "query.tenant", SeverityBreaking},
+		{KindEnumChanged, "GET", "query.status", SeverityBreaking},
+		{KindTypeChanged, "GET", "response.200[].id", SeverityBreaking},
+		{KindPropertyRemoved, "GET", "response.200[].email", SeverityBreaking},
+		{KindPropertyAdded, "GET", "response.200[].phone", SeverityNonBreaking},
+		{KindPropertyAdded, "POST", "body.nickname", SeverityNonBreaking},
+		{KindConstraintChanged, "POST", "body.name", SeverityBreaking},
+	}
+	for _, tc := range cases {
+		c := findChange(report, tc.kind, tc.method, tc.location)
+		if c == nil {
+			t.Errorf("missing %s %s %s in %+v", tc.kind, tc.method, tc.location, report.Changes)
+			continue
+		}
+		if c.Severity != tc.severity {
+			t.Errorf("%s %s severity = %s, want %s", tc.kind, tc.location, c.Severity, tc.severity)
+		}
+	}
+	if got := len(report.BreakingChanges()); got != report.Breaking {
+		t.Errorf("BreakingChanges() = %d, Breaking = %d", got, report.Breaking)
+	}
+}
+
+func TestCompareSecurity(t *testing.T) {
+	op := func(sec []map[string][]string) *openapi.Document {
+		doc := &openapi.Document{Paths: map[string]*openapi.PathItem{
+			"/x": {Get: &openapi.OperationObject{Security: sec}},
+		}}
+		return doc
+	}
+	bearer := []map[string][]string{{"bearer": {}}}
+	both := []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+
+	if r := Compare(op(nil), op(bearer)); !r.HasBreaking() {
+		t.Errorf("adding security should be breaking: %+v", r.Changes)
+	}
+	if r := Compare(op(bearer), op(both)); r.HasBreaking() || r.NonBreaking != 1 {
+		t.Errorf("adding alternative should be non-breaking: %+v", r.Changes)
+	}
+	if r := Compare(op(both), op(bearer)); !r.HasBreaking() {
+		t.Errorf("removing alternative should be breaking: %+v", r.Changes)
+	}
+}
+
+func TestCompareParsedAcrossVersions(t *testing.T) {
+	base := build(t, openapi.Version30, v1Ops()...)
+	head := build(t, openapi.Version31, v1Ops()...)
+
+	data, err := openapi.MarshalYAML(head)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	parsed, err := openapi.ParseDocument(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if report := Compare(base, parsed); len(report.Changes) != 0 {
+		t.Fatalf("3.0 and 3.1 renderings should be equivalent, got %+v", report.Changes)
+	}
+}
+
+func TestCompareUnionVariants(t *testing.T) {
+	doc := func(mapping map[string]string) *openapi.Document {
+		return &openapi.Document{Paths: map[string]*openapi.PathItem{
+			"/events": {Get: &openapi.OperationObject{Responses: map[string]*openapi.APIResponse{
+				"200": {Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{
+					Discriminator: &openapi.Discriminator{PropertyName: "type", Mapping: mapping},
+				}}}},
+			}}},
+		}}
+	}
+	one := map[string]string{"a": "#/components/schemas/A"}
+	two := map[string]string{"a": "#/components/schemas/A", "b": "#/components/schemas/B"}
+
+	if r := Compare(doc(one), doc(two)); !r.HasBreaking() || r.Changes[0].Kind != KindVariantChanged {
+		t.Errorf("new response variant should be breaking: %+v", r.Changes)
+	}
+	if r := Compare(doc(two), doc(one)); r.HasBreaking() || r.NonBreaking != 1 {
+		t.Errorf("removed response variant should be non-breaking: %+v", r.Changes)
+	}
+}
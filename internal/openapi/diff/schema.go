@@ -0,0 +1,307 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+// schemaView 是解析 $ref、合并 allOf、展开可空 anyOf 后的 schema 视图。
+type schemaView struct {
+	refs       []string // 经过的组件引用，用于检测循环
+	types      []string // 不含 "null"，已排序
+	nullable   bool
+	properties map[string]*openapi.Schema
+	required   map[string]bool
+	items      *openapi.Schema
+	additional *openapi.Schema
+	enum       []any
+	mapping    map[string]string // discriminator 判别值 → 引用
+
+	minLength, maxLength *int
+	minimum, maximum     *float64
+	minItems, maxItems   *int
+}
+
+// view 构建 schema 视图，doc 用于解析组件引用。
+func view(doc *openapi.Document, s *openapi.Schema) *schemaView {
+	v := &schemaView{properties: map[string]*openapi.Schema{}, required: map[string]bool{}}
+	v.merge(doc, s, map[string]bool{})
+	sort.Strings(v.types)
+	return v
+}
+
+func (v *schemaView) merge(doc *openapi.Document, s *openapi.Schema, seen map[string]bool) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		if seen[s.Ref] {
+			return
+		}
+		seen[s.Ref] = true
+		v.refs = append(v.refs, s.Ref)
+		if target := doc.Components.Schemas[strings.TrimPrefix(s.Ref, componentPrefix)]; target != nil {
+			v.merge(doc, target, seen)
+		}
+	}
+	for _, member := range s.AllOf {
+		v.merge(doc, member, seen)
+	}
+	// anyOf[X, {type: null}] 是 3.1 可空引用的写法，其余组合类型不展开
+	if variants := nonNullVariants(s.AnyOf); len(variants) < len(s.AnyOf) {
+		v.nullable = true
+		if len(variants) == 1 {
+			v.merge(doc, variants[0], seen)
+		}
+	}
+
+	if s.Type != "" && s.Type != "null" {
+		v.addType(s.Type)
+	}
+	for _, t := range s.Types {
+		if t == "null" {
+			v.nullable = true
+			continue
+		}
+		v.addType(t)
+	}
+	if s.Nullable {
+		v.nullable = true
+	}
+	for name, prop := range s.Properties {
+		v.properties[name] = prop
+	}
+	for _, name := range s.Required {
+		v.required[name] = true
+	}
+	if s.Items != nil {
+		v.items = s.Items
+	}
+	if s.AdditionalProperties != nil {
+		v.additional = s.AdditionalProperties
+	}
+	if len(s.Enum) > 0 {
+		v.enum = s.Enum
+	}
+	if s.Discriminator != nil && len(s.Discriminator.Mapping) > 0 {
+		v.mapping = s.Discriminator.Mapping
+	}
+	v.minLength = firstInt(v.minLength, s.MinLength)
+	v.maxLength = firstInt(v.maxLength, s.MaxLength)
+	v.minItems = firstInt(v.minItems, s.MinItems)
+	v.maxItems = firstInt(v.maxItems, s.MaxItems)
+	v.minimum = firstFloat(v.minimum, s.Minimum, s.ExclusiveMinimumValue)
+	v.maximum = firstFloat(v.maximum, s.Maximum, s.ExclusiveMaximumValue)
+}
+
+func (v *schemaView) addType(t string) {
+	for _, existing := range v.types {
+		if existing == t {
+			return
+		}
+	}
+	v.types = append(v.types, t)
+}
+
+func nonNullVariants(schemas []*openapi.Schema) []*openapi.Schema {
+	var out []*openapi.Schema
+	for _, s := range schemas {
+		if s == nil || (s.Ref == "" && (s.Type == "null" || (len(s.Types) == 1 && s.Types[0] == "null"))) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func firstInt(cur, next *int) *int {
+	if cur != nil {
+		return cur
+	}
+	return next
+}
+
+func firstFloat(cur *float64, next ...*float64) *float64 {
+	if cur != nil {
+		return cur
+	}
+	for _, v := range next {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// compareSchema 递归比较 schema，loc 为当前位置。
+func (c *comparer) compareSchema(loc string, bs, hs *openapi.Schema, dir direction) {
+	if bs == nil || hs == nil {
+		return
+	}
+	b, h := view(c.base, bs), view(c.head, hs)
+
+	// 递归类型：同一对组件在一条比较链上只比较一次
+	if len(b.refs) > 0 || len(h.refs) > 0 {
+		key := fmt.Sprintf("%d|%s|%s", dir, strings.Join(b.refs, ","), strings.Join(h.refs, ","))
+		if c.visiting[key] {
+			return
+		}
+		c.visiting[key] = true
+		defer delete(c.visiting, key)
+	}
+
+	if len(b.types) > 0 && len(h.types) > 0 && strings.Join(b.types, ",") != strings.Join(h.types, ",") {
+		c.add(KindTypeChanged, true, loc, "type changed from %s to %s", strings.Join(b.types, "|"), strings.Join(h.types, "|"))
+		return
+	}
+
+	switch {
+	case b.nullable && !h.nullable:
+		c.add(KindNullableChanged, dir == dirRequest, loc, "no longer nullable")
+	case !b.nullable && h.nullable:
+		c.add(KindNullableChanged, dir == dirResponse, loc, "became nullable")
+	}
+
+	c.compareEnum(loc, b.enum, h.enum, dir)
+	c.compareMapping(loc, b.mapping, h.mapping, dir)
+	if dir == dirRequest {
+		c.compareConstraints(loc, b, h)
+	}
+	c.compareProperties(loc, b, h, dir)
+
+	if b.items != nil && h.items != nil {
+		c.compareSchema(loc+"[]", b.items, h.items, dir)
+	}
+	if b.additional != nil && h.additional != nil {
+		c.compareSchema(loc+"{}", b.additional, h.additional, dir)
+	}
+}
+
+func (c *comparer) compareProperties(loc string, b, h *schemaView, dir direction) {
+	for _, name := range unionKeys(b.properties, h.properties) {
+		bp, hp := b.properties[name], h.properties[name]
+		ploc := loc + "." + name
+		switch {
+		case hp == nil:
+			c.add(KindPropertyRemoved, dir == dirResponse, ploc, "property removed")
+		case bp == nil:
+			if dir == dirRequest && h.required[name] {
+				c.add(KindPropertyAdded, true, ploc, "required property added")
+			} else {
+				c.add(KindPropertyAdded, false, ploc, "property added")
+			}
+		default:
+			br, hr := b.required[name], h.required[name]
+			switch {
+			case !br && hr:
+				c.add(KindRequiredChanged, dir == dirRequest, ploc, "property became required")
+			case br && !hr:
+				c.add(KindRequiredChanged, dir == dirResponse, ploc, "property became optional")
+			}
+			c.compareSchema(ploc, bp, hp, dir)
+		}
+	}
+}
+
+// compareEnum 请求侧收窄（删除取值或新增枚举限制）为 breaking，响应侧扩充为 breaking。
+func (c *comparer) compareEnum(loc string, base, head []any, dir direction) {
+	if len(base) == 0 && len(head) == 0 {
+		return
+	}
+	if len(base) == 0 {
+		c.add(KindEnumChanged, dir == dirRequest, loc, "enum restriction added: %s", formatValues(head))
+		return
+	}
+	if len(head) == 0 {
+		c.add(KindEnumChanged, dir == dirResponse, loc, "enum restriction removed")
+		return
+	}
+	removed, added := enumDiff(base, head), enumDiff(head, base)
+	if len(removed) > 0 {
+		c.add(KindEnumChanged, dir == dirRequest, loc, "enum values removed: %s", formatValues(removed))
+	}
+	if len(added) > 0 {
+		c.add(KindEnumChanged, dir == dirResponse, loc, "enum values added: %s", formatValues(added))
+	}
+}
+
+// compareMapping 比较多态类型的判别值，规则与枚举一致：请求侧删除实现、响应侧新增实现为 breaking。
+func (c *comparer) compareMapping(loc string, base, head map[string]string, dir direction) {
+	if len(base) == 0 || len(head) == 0 {
+		return
+	}
+	for _, value := range unionKeys(base, head) {
+		_, inBase := base[value]
+		_, inHead := head[value]
+		switch {
+		case !inHead:
+			c.add(KindVariantChanged, dir == dirRequest, loc, "variant %s removed", value)
+		case !inBase:
+			c.add(KindVariantChanged, dir == dirResponse, loc, "variant %s added", value)
+		}
+	}
+}
+
+// compareConstraints 比较请求侧长度、数值和数组长度约束。
+func (c *comparer) compareConstraints(loc string, b, h *schemaView) {
+	c.compareBound(loc, "minLength", intPtrToFloat(b.minLength), intPtrToFloat(h.minLength), true)
+	c.compareBound(loc, "maxLength", intPtrToFloat(b.maxLength), intPtrToFloat(h.maxLength), false)
+	c.compareBound(loc, "minimum", b.minimum, h.minimum, true)
+	c.compareBound(loc, "maximum", b.maximum, h.maximum, false)
+	c.compareBound(loc, "minItems", intPtrToFloat(b.minItems), intPtrToFloat(h.minItems), true)
+	c.compareBound(loc, "maxItems", intPtrToFloat(b.maxItems), intPtrToFloat(h.maxItems), false)
+}
+
+// compareBound lower 为 true 表示下界（变大为收紧），否则为上界（变小为收紧）。
+func (c *comparer) compareBound(loc, name string, b, h *float64, lower bool) {
+	switch {
+	case b == nil && h == nil:
+		return
+	case b == nil:
+		c.add(KindConstraintChanged, true, loc, "%s %v added", name, *h)
+	case h == nil:
+		c.add(KindConstraintChanged, false, loc, "%s %v removed", name, *b)
+	case *b != *h:
+		tightened := *h > *b
+		if !lower {
+			tightened = *h < *b
+		}
+		c.add(KindConstraintChanged, tightened, loc, "%s changed from %v to %v", name, *b, *h)
+	}
+}
+
+func intPtrToFloat(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+// enumDiff 返回 a 中不在 b 中的取值。
+func enumDiff(a, b []any) []any {
+	set := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		set[fmt.Sprint(v)] = struct{}{}
+	}
+	var out []any
+	for _, v := range a {
+		if _, ok := set[fmt.Sprint(v)]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
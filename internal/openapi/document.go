@@ -1,12 +1,13 @@
 package openapi
 
 type Document struct {
-	OpenAPI    string               `json:"openapi" yaml:"openapi"`
-	Info       Info                 `json:"info" yaml:"info"`
-	Servers    []Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
-	Components Components           `json:"components,omitempty" yaml:"components,omitempty"`
-	Tags       []Tag                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	OpenAPI           string               `json:"openapi" yaml:"openapi"`
+	Info              Info                 `json:"info" yaml:"info"`
+	JSONSchemaDialect string               `json:"jsonSchemaDialect,omitempty" yaml:"jsonSchemaDialect,omitempty"`
+	Servers           []Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths             map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components        Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	Tags              []Tag                `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 type Info struct {
@@ -76,42 +77,81 @@ type APIResponse struct {
 }
 
 type MediaType struct {
-	Schema  *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
-	Example any     `json:"example,omitempty" yaml:"example,omitempty"`
+	Schema   *Schema             `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  any                 `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty" yaml:"examples,omitempty"`
 }
 
+type Example struct {
+	Summary     string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Value       any    `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Schema 以 OpenAPI 3.0 字段为基础；3.1 文档中 Types、ExclusiveMinimumValue、
+// ExclusiveMaximumValue 非空时分别替代 type、exclusiveMinimum、exclusiveMaximum 输出，见 MarshalJSON。
 type Schema struct {
-	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
-	AllOf                []*Schema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
-	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
-	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
-	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
-	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
-	Deprecated           bool               `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
-	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
-	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
-	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
-	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
-	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
-	Default              any                `json:"default,omitempty" yaml:"default,omitempty"`
-	Example              any                `json:"example,omitempty" yaml:"example,omitempty"`
-	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
-	MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
-	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
-	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
-	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
-	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
-	MinItems             *int               `json:"minItems,omitempty" yaml:"minItems,omitempty"`
-	MaxItems             *int               `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
-	Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
-	XConstraints         map[string]string  `json:"x-constraints,omitempty" yaml:"x-constraints,omitempty"`
+	Ref                   string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	AllOf                 []*Schema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	OneOf                 []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                 []*Schema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	Discriminator         *Discriminator     `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	Type                  string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Types                 []string           `json:"-" yaml:"-"`
+	Format                string             `json:"format,omitempty" yaml:"format,omitempty"`
+	ContentMediaType      string             `json:"contentMediaType,omitempty" yaml:"contentMediaType,omitempty"`
+	Description           string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Nullable              bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Deprecated            bool               `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Properties            map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required              []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                 *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	AdditionalProperties  *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Enum                  []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default               any                `json:"default,omitempty" yaml:"default,omitempty"`
+	Example               any                `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples              []any              `json:"examples,omitempty" yaml:"examples,omitempty"`
+	MinLength             *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength             *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Minimum               *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum               *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMinimum      bool               `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum      bool               `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	ExclusiveMinimumValue *float64           `json:"-" yaml:"-"`
+	ExclusiveMaximumValue *float64           `json:"-" yaml:"-"`
+	MinItems              *int               `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems              *int               `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	Pattern               string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	XConstraints          map[string]string  `json:"x-constraints,omitempty" yaml:"x-constraints,omitempty"`
+}
+
+// Discriminator 描述 oneOf/anyOf 的判别属性，Mapping 为判别值到 schema 引用的映射。
+type Discriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
 }
 
 type SecurityScheme struct {
-	Type         string `json:"type,omitempty" yaml:"type,omitempty"`
-	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
-	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
-	In           string `json:"in,omitempty" yaml:"in,omitempty"`
-	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
-	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	Type             string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Description      string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Name             string      `json:"name,omitempty" yaml:"name,omitempty"`
+	In               string      `json:"in,omitempty" yaml:"in,omitempty"`
+	Scheme           string      `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat     string      `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	Flows            *OAuthFlows `json:"flows,omitempty" yaml:"flows,omitempty"`
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty" yaml:"openIdConnectUrl,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty" yaml:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty" yaml:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty" yaml:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
 }
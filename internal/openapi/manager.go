@@ -1,5 +1,7 @@
 package openapi
 
+import "fmt"
+
 type Manager struct {
 	registry *Registry
 	analyzer *Analyzer
@@ -18,6 +20,7 @@ func New(opts ...Option) *Manager {
 		FieldNamer:          cfg.FieldNamer,
 		DescriptionProvider: cfg.DescriptionProvider,
 		ConstraintParser:    cfg.ConstraintParser,
+		Unions:              cfg.Unions,
 	}
 
 	return &Manager{
@@ -29,6 +32,13 @@ func New(opts ...Option) *Manager {
 }
 
 func (m *Manager) AddOperation(op Operation) error {
+	for _, sec := range op.Security {
+		for name := range sec {
+			if _, ok := m.opts.SecuritySchemes[name]; !ok {
+				return fmt.Errorf("openapi: undefined security scheme %q in %s %s", name, op.Method, op.Path)
+			}
+		}
+	}
 	return m.registry.Add(op)
 }
 
@@ -106,6 +116,7 @@ func (m *Manager) CloneWithOptions(opts ...Option) *Manager {
 			FieldNamer:          cfg.FieldNamer,
 			DescriptionProvider: cfg.DescriptionProvider,
 			ConstraintParser:    cfg.ConstraintParser,
+			Unions:              cfg.Unions,
 		}),
 		builder: NewBuilder(cfg),
 		opts:    cfg,
@@ -1,7 +1,9 @@
 package openapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 
 	gyaml "github.com/goccy/go-yaml"
 )
@@ -26,6 +28,105 @@ func MarshalJSON(doc *Document) ([]byte, error) {
 	return json.MarshalIndent(doc, "", "  ")
 }
 
+// MarshalYAML 复用 Schema 的 JSON 序列化逻辑，保证 3.1 文档的 YAML 与 JSON 结构一致。
 func MarshalYAML(doc *Document) ([]byte, error) {
-	return gyaml.Marshal(doc)
+	return gyaml.MarshalWithOptions(doc, gyaml.UseJSONMarshaler())
+}
+
+// schemaJSON 是去掉 MarshalJSON 方法的 Schema，避免递归调用。
+type schemaJSON Schema
+
+// MarshalJSON 在设置了 3.1 专用字段时以类型数组和数值型 exclusive 边界覆盖 3.0 字段。
+func (s Schema) MarshalJSON() ([]byte, error) {
+	if len(s.Types) == 0 && s.ExclusiveMinimumValue == nil && s.ExclusiveMaximumValue == nil {
+		return json.Marshal(schemaJSON(s))
+	}
+	out := struct {
+		schemaJSON
+		Type             any      `json:"type,omitempty"`
+		ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	}{
+		schemaJSON:       schemaJSON(s),
+		ExclusiveMinimum: s.ExclusiveMinimumValue,
+		ExclusiveMaximum: s.ExclusiveMaximumValue,
+	}
+	switch {
+	case len(s.Types) == 1:
+		out.Type = s.Types[0]
+	case len(s.Types) > 1:
+		out.Type = s.Types
+	case s.Type != "":
+		out.Type = s.Type
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 同时接受 3.0 与 3.1 写法：type 可为字符串或数组，exclusiveMinimum/Maximum 可为布尔或数值。
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var in struct {
+		schemaJSON
+		Type             json.RawMessage `json:"type,omitempty"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum,omitempty"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*s = Schema(in.schemaJSON)
+
+	if len(in.Type) > 0 {
+		if in.Type[0] == '[' {
+			if err := json.Unmarshal(in.Type, &s.Types); err != nil {
+				return err
+			}
+		} else if err := json.Unmarshal(in.Type, &s.Type); err != nil {
+			return err
+		}
+	}
+	var err error
+	if s.ExclusiveMinimum, s.ExclusiveMinimumValue, err = parseExclusiveBound(in.ExclusiveMinimum); err != nil {
+		return err
+	}
+	if s.ExclusiveMaximum, s.ExclusiveMaximumValue, err = parseExclusiveBound(in.ExclusiveMaximum); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseExclusiveBound 解析 3.0 布尔或 3.1 数值形式的 exclusive 边界。
+func parseExclusiveBound(raw json.RawMessage) (bool, *float64, error) {
+	switch {
+	case len(raw) == 0:
+		return false, nil, nil
+	case bytes.Equal(raw, []byte("true")):
+		return true, nil, nil
+	case bytes.Equal(raw, []byte("false")):
+		return false, nil, nil
+	}
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return false, nil, fmt.Errorf("openapi: invalid exclusive bound %s", raw)
+	}
+	return false, &v, nil
+}
+
+// ParseDocument 解析 JSON 或 YAML 格式的 OpenAPI 文档（3.0 或 3.1）。
+func ParseDocument(data []byte) (*Document, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("openapi: empty document")
+	}
+	if trimmed[0] != '{' {
+		converted, err := gyaml.YAMLToJSON(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: parse yaml: %w", err)
+		}
+		trimmed = converted
+	}
+	var doc Document
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+	return &doc, nil
 }
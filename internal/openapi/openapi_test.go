@@ -1,6 +1,10 @@
 package openapi
 
-import "testing"
+import (
+	"mime/multipart"
+	"strings"
+	"testing"
+)
 
 type sharedType struct {
 	UserID int `json:"user_id" form:"userId" binding:"gte=1" desc:"user id"`
@@ -430,3 +434,401 @@ func TestUriFieldsExcludedFromQueryParams(t *testing.T) {
 		t.Fatal("query param 'name' not found")
 	}
 }
+
+type envelopeResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data" desc:"payload"`
+}
+
+func TestBuildWrapsResponsesWithEnvelope(t *testing.T) {
+	m := New(WithResponseEnvelope("Response", envelopeResp{}, "data"))
+	m.MustAddOperations(
+		Operation{Method: "GET", Path: "/shared", Responses: []Response{{Status: 200, Body: sharedType{}}}},
+		Operation{Method: "GET", Path: "/list", Responses: []Response{{Status: 200, Body: []string{}}}},
+		Operation{Method: "DELETE", Path: "/shared", Responses: []Response{{Status: 200}}},
+		Operation{Method: "GET", Path: "/raw", Responses: []Response{{Status: 200, Body: sharedType{}, Raw: true}}},
+	)
+
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	base := doc.Components.Schemas["Response"]
+	if base == nil {
+		t.Fatal("missing Response envelope component")
+	}
+	if data := base.Properties["data"]; data == nil || data.Type != "" || data.Description != "payload" {
+		t.Fatalf("envelope data property should be untyped, got %#v", data)
+	}
+
+	named := doc.Paths["/shared"].Get.Responses["200"].Content["application/json"].Schema
+	wantName := "Response_github.com.tokmz.qi.internal.openapi.sharedType"
+	if named.Ref != "#/components/schemas/"+wantName {
+		t.Fatalf("named payload should ref wrapper component, got %#v", named)
+	}
+	wrapper := doc.Components.Schemas[wantName]
+	if wrapper == nil || len(wrapper.AllOf) != 2 || wrapper.AllOf[0].Ref != "#/components/schemas/Response" {
+		t.Fatalf("unexpected wrapper component: %#v", wrapper)
+	}
+	if wrapper.AllOf[1].Properties["data"].Ref != "#/components/schemas/github.com.tokmz.qi.internal.openapi.sharedType.Response" {
+		t.Fatalf("wrapper data should ref payload component, got %#v", wrapper.AllOf[1].Properties["data"])
+	}
+
+	inline := doc.Paths["/list"].Get.Responses["200"].Content["application/json"].Schema
+	if len(inline.AllOf) != 2 || inline.AllOf[1].Properties["data"].Type != "array" {
+		t.Fatalf("inline payload should be wrapped with allOf, got %#v", inline)
+	}
+
+	empty := doc.Paths["/shared"].Delete.Responses["200"].Content["application/json"].Schema
+	if empty.Ref != "#/components/schemas/Response" {
+		t.Fatalf("response without body should ref envelope, got %#v", empty)
+	}
+
+	raw := doc.Paths["/raw"].Get.Responses["200"].Content["application/json"].Schema
+	if raw.Ref != "#/components/schemas/github.com.tokmz.qi.internal.openapi.sharedType.Response" {
+		t.Fatalf("raw response should not be wrapped, got %#v", raw)
+	}
+}
+
+func TestBuildRejectsEnvelopeWithoutDataField(t *testing.T) {
+	m := New(WithResponseEnvelope("Response", envelopeResp{}, "payload"))
+	m.MustAddOperation(Operation{Method: "GET", Path: "/users"})
+	if _, err := m.Build(); err == nil {
+		t.Fatal("expected error for missing envelope data field")
+	}
+}
+
+func TestBuildResponseExamples(t *testing.T) {
+	m := New()
+	m.MustAddOperation(Operation{
+		Method: "GET",
+		Path:   "/users",
+		Responses: []Response{{
+			Status:      404,
+			Description: "Not Found",
+			Examples: []ResponseExample{
+				{Name: "2001", Summary: "user not found", Value: map[string]any{"code": 2001}},
+			},
+		}},
+	})
+
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	media := doc.Paths["/users"].Get.Responses["404"].Content["application/json"]
+	if media == nil {
+		t.Fatal("examples-only response should still produce content")
+	}
+	if ex := media.Examples["2001"]; ex == nil || ex.Summary != "user not found" {
+		t.Fatalf("unexpected examples: %#v", media.Examples)
+	}
+}
+
+type uploadReq struct {
+	ID     string                  `uri:"id"`
+	Title  string                  `form:"title" json:"ignored" binding:"required"`
+	File   *multipart.FileHeader   `form:"file" binding:"required"`
+	Images []*multipart.FileHeader `form:"images"`
+}
+
+func TestBuildMultipartFormBody(t *testing.T) {
+	m := New()
+	m.MustAddOperation(Operation{
+		Method: "POST",
+		Path:   "/uploads",
+		Request: &Request{
+			Body:            uploadReq{},
+			BodyContentType: "multipart/form-data",
+		},
+	})
+
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	media := doc.Paths["/uploads"].Post.RequestBody.Content["multipart/form-data"]
+	if media == nil || media.Schema.Ref != "#/components/schemas/github.com.tokmz.qi.internal.openapi.uploadReq.Form" {
+		t.Fatalf("unexpected multipart body: %#v", media)
+	}
+
+	schema := doc.Components.Schemas["github.com.tokmz.qi.internal.openapi.uploadReq.Form"]
+	if _, ok := schema.Properties["title"]; !ok {
+		t.Fatalf("form body should use form tag, got %v", schema.Properties)
+	}
+	if _, ok := schema.Properties["id"]; ok {
+		t.Fatal("uri-only field should be excluded from form body")
+	}
+	file := schema.Properties["file"]
+	if file == nil || file.Type != "string" || file.Format != "binary" || file.Nullable {
+		t.Fatalf("file should be binary string, got %#v", file)
+	}
+	images := schema.Properties["images"]
+	if images == nil || images.Type != "array" || images.Items.Format != "binary" {
+		t.Fatalf("images should be array of binary, got %#v", images)
+	}
+	if len(schema.Required) != 2 {
+		t.Fatalf("required = %v, want [file title]", schema.Required)
+	}
+}
+
+func TestCloneWithOperationFilter(t *testing.T) {
+	m := New()
+	m.MustAddOperations(
+		Operation{Method: "GET", Path: "/admin/users", Tags: []string{"admin"}},
+		Operation{Method: "GET", Path: "/admin/stats", Tags: []string{"stats"}},
+		Operation{Method: "GET", Path: "/news", Tags: []string{"public"}},
+	)
+
+	admin := m.CloneWithOptions(WithOperationFilter(FilterByPathPrefix("/admin")))
+	doc, err := admin.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(doc.Paths) != 2 || doc.Paths["/news"] != nil {
+		t.Fatalf("prefix filter paths = %v", doc.Paths)
+	}
+
+	// 再次追加过滤器时两者都需满足
+	doc, err = admin.CloneWithOptions(WithOperationFilter(FilterByTags("admin"))).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(doc.Paths) != 1 || doc.Paths["/admin/users"] == nil {
+		t.Fatalf("combined filter paths = %v", doc.Paths)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0].Name != "admin" {
+		t.Fatalf("tags should only include filtered operations, got %v", doc.Tags)
+	}
+
+	if doc := m.MustBuild(); len(doc.Paths) != 3 {
+		t.Fatalf("original manager should not be filtered, got %v", doc.Paths)
+	}
+}
+
+type version31Req struct {
+	Age    *int                  `json:"age" binding:"gt=0,lt=150" example:"18"`
+	Shared *sharedType           `json:"shared" desc:"wrapped shared"`
+	Avatar *multipart.FileHeader `form:"avatar"`
+}
+
+func TestBuildOpenAPI31(t *testing.T) {
+	m := New(WithOpenAPIVersion(Version31))
+	m.MustAddOperation(Operation{
+		Method:    "POST",
+		Path:      "/v31",
+		Request:   &Request{Body: version31Req{}},
+		Responses: []Response{{Status: 200, Body: version31Req{}}},
+	})
+
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if doc.OpenAPI != Version31 || doc.JSONSchemaDialect != JSONSchemaDialect31 {
+		t.Fatalf("unexpected version header: %q %q", doc.OpenAPI, doc.JSONSchemaDialect)
+	}
+
+	body := doc.Components.Schemas["github.com.tokmz.qi.internal.openapi.version31Req.Body"]
+	if body == nil {
+		t.Fatalf("missing body schema")
+	}
+	age := body.Properties["age"]
+	if len(age.Types) != 2 || age.Types[0] != "integer" || age.Types[1] != "null" || age.Nullable {
+		t.Fatalf("nullable should become type array, got %#v", age)
+	}
+	if age.ExclusiveMinimumValue == nil || *age.ExclusiveMinimumValue != 0 || age.Minimum != nil {
+		t.Fatalf("exclusiveMinimum should be numeric, got %#v", age)
+	}
+	if age.ExclusiveMaximumValue == nil || *age.ExclusiveMaximumValue != 150 || age.Maximum != nil {
+		t.Fatalf("exclusiveMaximum should be numeric, got %#v", age)
+	}
+	if age.Example != nil || len(age.Examples) != 1 {
+		t.Fatalf("example should become examples, got %#v", age)
+	}
+
+	shared := body.Properties["shared"]
+	if len(shared.AllOf) != 0 || len(shared.AnyOf) != 2 || shared.AnyOf[0].Ref == "" || shared.AnyOf[1].Type != "null" {
+		t.Fatalf("nullable ref should become anyOf, got %#v", shared)
+	}
+	if shared.Description != "wrapped shared" {
+		t.Fatalf("ref metadata should be kept, got %#v", shared)
+	}
+
+	data, err := MarshalJSON(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{`"type": [`, `"exclusiveMinimum": 0`, `"exclusiveMaximum": 150`, `"examples": [`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("json should contain %s:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), `"nullable"`) {
+		t.Fatalf("json should not contain nullable:\n%s", data)
+	}
+	yml, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatalf("marshal yaml: %v", err)
+	}
+	if !strings.Contains(string(yml), "jsonSchemaDialect:") || strings.Contains(string(yml), "nullable:") {
+		t.Fatalf("unexpected yaml:\n%s", yml)
+	}
+}
+
+func TestBuildOpenAPI31FileContentMediaType(t *testing.T) {
+	m := New(WithOpenAPIVersion(Version31))
+	m.MustAddOperation(Operation{
+		Method:  "POST",
+		Path:    "/upload",
+		Request: &Request{Body: version31Req{}, BodyContentType: "multipart/form-data"},
+	})
+	doc := m.MustBuild()
+	form := doc.Components.Schemas["github.com.tokmz.qi.internal.openapi.version31Req.Form"]
+	if form == nil {
+		t.Fatalf("missing form schema")
+	}
+	avatar := form.Properties["avatar"]
+	if avatar.Format != "" || avatar.ContentMediaType != "application/octet-stream" {
+		t.Fatalf("binary file should use contentMediaType, got %#v", avatar)
+	}
+}
+
+func TestBuildRejectsUnsupportedVersion(t *testing.T) {
+	if _, err := New(WithOpenAPIVersion("2.0")).Build(); err == nil {
+		t.Fatalf("expected unsupported version error")
+	}
+}
+
+type event interface{ eventType() string }
+
+type userCreated struct {
+	Type   string `json:"type"`
+	UserID int    `json:"user_id"`
+}
+
+func (userCreated) eventType() string { return "user.created" }
+
+type orderPaid struct {
+	Type    string  `json:"type"`
+	OrderID string  `json:"order_id"`
+	Amount  float64 `json:"amount"`
+}
+
+func (orderPaid) eventType() string { return "order.paid" }
+
+type eventBatch struct {
+	Latest event   `json:"latest"`
+	Events []event `json:"events"`
+	Extra  any     `json:"extra"`
+}
+
+func TestBuildUnionWithDiscriminator(t *testing.T) {
+	m := New(WithUnion(OneOf[event]("type",
+		Variant("user.created", userCreated{}),
+		Variant("order.paid", &orderPaid{}),
+	)))
+	m.MustAddOperation(Operation{
+		Method:    "GET",
+		Path:      "/events",
+		Responses: []Response{{Status: 200, Body: eventBatch{}}},
+	})
+	doc := m.MustBuild()
+
+	const prefix = "github.com.tokmz.qi.internal.openapi."
+	union := doc.Components.Schemas[prefix+"event.Response"]
+	if union == nil {
+		t.Fatalf("missing union component, got %v", mapKeysOf(doc.Components.Schemas))
+	}
+	if len(union.OneOf) != 2 || union.OneOf[0].Ref != "#/components/schemas/"+prefix+"userCreated.Response" {
+		t.Fatalf("unexpected oneOf: %#v", union.OneOf)
+	}
+	if union.Discriminator == nil || union.Discriminator.PropertyName != "type" ||
+		union.Discriminator.Mapping["order.paid"] != "#/components/schemas/"+prefix+"orderPaid.Response" {
+		t.Fatalf("unexpected discriminator: %#v", union.Discriminator)
+	}
+
+	batch := doc.Components.Schemas[prefix+"eventBatch.Response"]
+	if batch.Properties["latest"].Ref != "#/components/schemas/"+prefix+"event.Response" {
+		t.Fatalf("interface field should ref union, got %#v", batch.Properties["latest"])
+	}
+	if batch.Properties["events"].Items.Ref != "#/components/schemas/"+prefix+"event.Response" {
+		t.Fatalf("slice items should ref union, got %#v", batch.Properties["events"].Items)
+	}
+	if extra := batch.Properties["extra"]; extra.Type != "" {
+		t.Fatalf("unregistered interface should accept any value, got %#v", extra)
+	}
+}
+
+func TestBuildUnionAnyOf(t *testing.T) {
+	m := New(WithUnion(AnyOf[event](userCreated{}, orderPaid{})), WithOpenAPIVersion(Version31))
+	m.MustAddOperation(Operation{
+		Method:  "POST",
+		Path:    "/events",
+		Request: &Request{Body: eventBatch{}},
+	})
+	doc := m.MustBuild()
+	union := doc.Components.Schemas["github.com.tokmz.qi.internal.openapi.event.Body"]
+	if union == nil || len(union.AnyOf) != 2 || len(union.OneOf) != 0 || union.Discriminator != nil {
+		t.Fatalf("unexpected anyOf union: %#v", union)
+	}
+}
+
+func mapKeysOf[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestSourceTagsSplitParameters(t *testing.T) {
+	type mixedReq struct {
+		ID      int64  `uri:"id"`
+		Page    int    `form:"page"`
+		Tenant  string `header:"X-Tenant-Id"`
+		Session string `cookie:"session"`
+		Name    string `json:"name"`
+	}
+
+	a := NewAnalyzer(AnalyzeOptions{})
+	want := map[ParamIn][]string{
+		ParamInPath:   {"id"},
+		ParamInQuery:  {"page"},
+		ParamInHeader: {"X-Tenant-Id"},
+		ParamInCookie: {"session"},
+	}
+	for in, names := range want {
+		params, err := a.AnalyzeParameters(mixedReq{}, in)
+		if err != nil {
+			t.Fatalf("analyze %s: %v", in, err)
+		}
+		got := make([]string, len(params))
+		for i, p := range params {
+			got[i] = p.Name
+		}
+		if strings.Join(got, ",") != strings.Join(names, ",") {
+			t.Errorf("%s params = %v, want %v", in, got, names)
+		}
+	}
+
+	if _, err := a.AnalyzeBody(mixedReq{}); err != nil {
+		t.Fatalf("analyze body: %v", err)
+	}
+	var body *SchemaNode
+	for _, c := range a.Components() {
+		if strings.HasSuffix(c.Name, "mixedReq.Body") {
+			body = c
+		}
+	}
+	if body == nil {
+		t.Fatal("missing body component")
+	}
+	for _, name := range []string{"ID", "Page", "Tenant", "Session"} {
+		if body.Properties[name] != nil {
+			t.Errorf("body should not contain %s", name)
+		}
+	}
+}
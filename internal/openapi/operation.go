@@ -25,6 +25,10 @@ type Request struct {
 
 	BodyRequired    bool
 	BodyContentType string
+
+	// 公共参数（如分组级请求头），先于 Headers/Cookies 展开，同名参数以后者为准
+	CommonHeaders []any
+	CommonCookies []any
 }
 
 type Response struct {
@@ -32,4 +36,14 @@ type Response struct {
 	Description string
 	Body        any
 	ContentType string
+	MediaTypes  []string // 多个媒体类型共用同一 schema（内容协商），设置时忽略 ContentType
+	Raw         bool     // 为 true 时跳过统一响应包装，Body 原样输出
+	Examples    []ResponseExample
+}
+
+// ResponseExample 命名响应示例，输出到 content.examples。
+type ResponseExample struct {
+	Name    string
+	Summary string
+	Value   any
 }
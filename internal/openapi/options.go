@@ -1,6 +1,13 @@
 package openapi
 
+import (
+	"reflect"
+	"strings"
+)
+
 type Options struct {
+	OpenAPIVersion string // 输出文档版本，Version30（默认）或 Version31
+
 	Title       string
 	Description string
 	Version     string
@@ -11,12 +18,24 @@ type Options struct {
 	DescriptionProvider DescriptionProvider
 	ConstraintParser    ConstraintParser
 	SecuritySchemes     map[string]*SecurityScheme
+	ResponseEnvelope    *Envelope
+	Unions              map[reflect.Type]Union
+	OperationFilter     func(Operation) bool
+}
+
+// Envelope 描述统一响应包装结构，启用后响应 schema 会被包装为 Envelope 组件，
+// 业务数据放在 DataField 属性中。
+type Envelope struct {
+	Name      string // 组件名，如 "Response"
+	Type      any    // 包装结构体样例，如 qi.Response{}
+	DataField string // 承载业务数据的属性名，如 "data"
 }
 
 type Option func(*Options)
 
 func defaultOptions() Options {
 	return Options{
+		OpenAPIVersion:      Version30,
 		Title:               "OpenAPI",
 		Version:             "1.0.0",
 		NameResolver:        DefaultNameResolver{},
@@ -26,6 +45,16 @@ func defaultOptions() Options {
 	}
 }
 
+// WithOpenAPIVersion 设置输出的 OpenAPI 版本，如 Version31。
+// 3.1 下可空类型输出为 type 数组、exclusive 边界为数值、example 为 examples 数组，并声明 jsonSchemaDialect。
+func WithOpenAPIVersion(v string) Option {
+	return func(o *Options) {
+		if v != "" {
+			o.OpenAPIVersion = v
+		}
+	}
+}
+
 func WithTitle(v string) Option {
 	return func(o *Options) {
 		o.Title = v
@@ -69,7 +98,7 @@ func WithFieldNamer(v FieldNamer) Option {
 // WithRecommendedDefaults 应用推荐的默认策略。
 // 当前主要收敛字段命名策略：
 // - body/response: 保持 Go 字段名回退
-// - query/path/cookie: snake_case
+// - query/form/path/cookie: snake_case
 // - header: kebab-case
 func WithRecommendedDefaults() Option {
 	return func(o *Options) {
@@ -105,3 +134,69 @@ func WithSecurityScheme(name string, scheme *SecurityScheme) Option {
 		o.SecuritySchemes[name] = &cp
 	}
 }
+
+// WithResponseEnvelope 设置统一响应包装。
+// 未标记 Raw 的响应会生成 allOf[Envelope, {DataField: 业务类型}] 结构。
+func WithResponseEnvelope(name string, sample any, dataField string) Option {
+	return func(o *Options) {
+		if name == "" || sample == nil || dataField == "" {
+			o.ResponseEnvelope = nil
+			return
+		}
+		o.ResponseEnvelope = &Envelope{Name: name, Type: sample, DataField: dataField}
+	}
+}
+
+// WithOperationFilter 追加操作过滤器，Build 时仅输出 filter 返回 true 的操作，
+// 已有过滤器时两者都需满足。常与 CloneWithOptions 配合，从同一 Manager 生成按标签或路径拆分的子文档。
+func WithOperationFilter(filter func(Operation) bool) Option {
+	return func(o *Options) {
+		if filter == nil {
+			return
+		}
+		if o.OperationFilter != nil {
+			filter = FilterAll(o.OperationFilter, filter)
+		}
+		o.OperationFilter = filter
+	}
+}
+
+// FilterByTags 返回匹配任一标签的操作过滤器。
+func FilterByTags(tags ...string) func(Operation) bool {
+	set := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		set[tag] = struct{}{}
+	}
+	return func(op Operation) bool {
+		for _, tag := range op.Tags {
+			if _, ok := set[tag]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterByPathPrefix 返回路径以任一前缀开头的操作过滤器。
+func FilterByPathPrefix(prefixes ...string) func(Operation) bool {
+	return func(op Operation) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(op.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterAll 组合多个过滤器，全部满足才保留，nil 过滤器被忽略。
+func FilterAll(filters ...func(Operation) bool) func(Operation) bool {
+	return func(op Operation) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(op) {
+				return false
+			}
+		}
+		return true
+	}
+}
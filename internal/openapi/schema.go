@@ -6,6 +6,7 @@ type AnalyzeMode string
 
 const (
 	AnalyzeModeBody     AnalyzeMode = "body"
+	AnalyzeModeForm     AnalyzeMode = "form"
 	AnalyzeModeResponse AnalyzeMode = "response"
 	AnalyzeModePath     AnalyzeMode = "path"
 	AnalyzeModeQuery    AnalyzeMode = "query"
@@ -38,6 +39,10 @@ type SchemaNode struct {
 	Items                *SchemaNode
 	AdditionalProperties *SchemaNode
 
+	OneOf         []*SchemaNode
+	AnyOf         []*SchemaNode
+	Discriminator *Discriminator
+
 	Enum    []any
 	Default any
 	Example any
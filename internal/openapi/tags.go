@@ -40,12 +40,11 @@ func ParseFieldTags(field reflect.StructField, mode AnalyzeMode, namer FieldName
 		info.Name = name
 	}
 
-	// 查询参数模式下，跳过仅有 uri tag 且无 form tag 的字段（避免与路径参数重复）
-	if mode == AnalyzeModeQuery && raw == "" {
-		if field.Tag.Get("uri") != "" {
-			info.Ignore = true
-			return info, nil
-		}
+	// 没有当前模式的 tag、但声明了其他参数位置 tag 的字段属于其他位置（如查询参数模式下仅有 uri tag 的字段），
+	// 跳过以避免同一结构体的字段在路径参数、查询参数、请求头、Cookie 和请求体之间重复
+	if raw == "" && hasOtherSourceTag(field, mode) {
+		info.Ignore = true
+		return info, nil
 	}
 
 	if openapiTag := field.Tag.Get("openapi"); openapiTag != "" {
@@ -155,7 +154,7 @@ func parseNameTag(tag string) (string, bool) {
 
 func tagForMode(mode AnalyzeMode) string {
 	switch mode {
-	case AnalyzeModeQuery:
+	case AnalyzeModeQuery, AnalyzeModeForm:
 		return "form"
 	case AnalyzeModePath:
 		return "uri"
@@ -168,6 +167,27 @@ func tagForMode(mode AnalyzeMode) string {
 	}
 }
 
+// otherSourceTags 各模式下标记字段属于其他参数位置的 tag。
+// 查询参数/表单模式不包含 json，仅有 json tag 的字段仍按字段名绑定查询参数；
+// JSON 请求体模式包含 form，仅有 form tag 的字段属于查询参数。
+var otherSourceTags = map[AnalyzeMode][]string{
+	AnalyzeModeQuery:  {"uri", "header", "cookie"},
+	AnalyzeModeForm:   {"uri", "header", "cookie"},
+	AnalyzeModeBody:   {"uri", "form", "header", "cookie"},
+	AnalyzeModePath:   {"json", "form", "header", "cookie"},
+	AnalyzeModeHeader: {"json", "form", "uri", "cookie"},
+	AnalyzeModeCookie: {"json", "form", "uri", "header"},
+}
+
+func hasOtherSourceTag(field reflect.StructField, mode AnalyzeMode) bool {
+	for _, tag := range otherSourceTags[mode] {
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func splitCSV(v string) []string {
 	if v == "" {
 		return nil
@@ -237,7 +257,7 @@ type RecommendedFieldNamer struct{}
 
 func (RecommendedFieldNamer) FieldName(field reflect.StructField, mode AnalyzeMode) string {
 	switch mode {
-	case AnalyzeModeQuery, AnalyzeModePath, AnalyzeModeCookie:
+	case AnalyzeModeQuery, AnalyzeModeForm, AnalyzeModePath, AnalyzeModeCookie:
 		return SnakeCaseFieldNamer{}.FieldName(field, mode)
 	case AnalyzeModeHeader:
 		return KebabCaseFieldNamer{}.FieldName(field, mode)
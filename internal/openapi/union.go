@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Union 描述接口类型的多态实现。分析到该类型（字段、切片元素、请求/响应体）时生成
+// oneOf（AnyOf 为 true 时为 anyOf），配置 Discriminator 时按判别值生成 discriminator.mapping。
+// 实现类型应声明判别属性（如 `json:"type"`），否则客户端无法按 discriminator 反序列化。
+type Union struct {
+	Type          reflect.Type // 接口类型，如 reflect.TypeOf((*Event)(nil)).Elem()
+	Discriminator string       // 判别属性名，如 "type"；为空时不生成 discriminator
+	Variants      []UnionVariant
+	AnyOf         bool
+}
+
+// UnionVariant 是 Union 的一个实现。
+type UnionVariant struct {
+	Value string // 判别值，如 "user.created"；未设置 Discriminator 时忽略
+	Type  any    // 实现类型样例，如 UserCreated{}
+}
+
+// Variant 创建一个 UnionVariant。
+func Variant(value string, sample any) UnionVariant {
+	return UnionVariant{Value: value, Type: sample}
+}
+
+// OneOf 创建接口 T 的 oneOf Union。
+func OneOf[T any](discriminator string, variants ...UnionVariant) Union {
+	return Union{
+		Type:          reflect.TypeOf((*T)(nil)).Elem(),
+		Discriminator: discriminator,
+		Variants:      append([]UnionVariant(nil), variants...),
+	}
+}
+
+// AnyOf 创建接口 T 的 anyOf Union，不生成 discriminator。
+func AnyOf[T any](samples ...any) Union {
+	u := Union{Type: reflect.TypeOf((*T)(nil)).Elem(), AnyOf: true}
+	for _, sample := range samples {
+		u.Variants = append(u.Variants, UnionVariant{Type: sample})
+	}
+	return u
+}
+
+// WithUnion 注册多态类型，同一类型重复注册时以后者为准。
+func WithUnion(u Union) Option {
+	return func(o *Options) {
+		if u.Type == nil || len(u.Variants) == 0 {
+			return
+		}
+		unions := make(map[reflect.Type]Union, len(o.Unions)+1)
+		for k, v := range o.Unions {
+			unions[k] = v
+		}
+		unions[u.Type] = u
+		o.Unions = unions
+	}
+}
+
+// analyzeUnion 生成多态 schema，具名类型注册为组件。
+func (a *Analyzer) analyzeUnion(t reflect.Type, u Union, mode AnalyzeMode) (*SchemaNode, error) {
+	build := func() (*SchemaNode, error) {
+		node := &SchemaNode{GoType: t, Mode: mode}
+		if u.Discriminator != "" {
+			node.Discriminator = &Discriminator{PropertyName: u.Discriminator}
+		}
+		for _, variant := range u.Variants {
+			vt := indirectType(typeOf(variant.Type))
+			if vt == nil {
+				return nil, fmt.Errorf("openapi: union %s has nil variant", t.String())
+			}
+			vn, err := a.analyzeSchema(vt, mode)
+			if err != nil {
+				return nil, err
+			}
+			if u.AnyOf {
+				node.AnyOf = append(node.AnyOf, vn)
+			} else {
+				node.OneOf = append(node.OneOf, vn)
+			}
+			if node.Discriminator != nil && variant.Value != "" && vn.Ref != "" {
+				if node.Discriminator.Mapping == nil {
+					node.Discriminator.Mapping = make(map[string]string)
+				}
+				node.Discriminator.Mapping[variant.Value] = vn.Ref
+			}
+		}
+		return node, nil
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		return build()
+	}
+	return a.analyzeComponent(t, mode, build)
+}
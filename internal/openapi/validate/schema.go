@@ -0,0 +1,386 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+// maxDepth 防止自引用 schema 与深层嵌套数据导致无限递归。
+const maxDepth = 64
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// check 校验 JSON 值（encoding/json 解码结果）是否符合 schema。
+func (v *Validator) check(s *openapi.Schema, val any, field, in string) []FieldError {
+	return v.checkDepth(s, val, field, in, 0)
+}
+
+func (v *Validator) checkDepth(s *openapi.Schema, val any, field, in string, depth int) []FieldError {
+	s = v.resolve(s)
+	if s == nil || depth > maxDepth {
+		return nil
+	}
+	name := field
+	if name == "" {
+		name = "body"
+	}
+
+	if val == nil {
+		if isNullable(s) || len(types(s)) == 0 && len(s.AllOf) == 0 && len(s.OneOf) == 0 && len(s.AnyOf) == 0 {
+			return nil
+		}
+		return []FieldError{{Field: name, In: in, Rule: "nullable", Message: "must not be null"}}
+	}
+
+	var errs []FieldError
+	for _, sub := range s.AllOf {
+		errs = append(errs, v.checkDepth(sub, val, field, in, depth+1)...)
+	}
+	if variants := nonNull(append(append([]*openapi.Schema(nil), s.OneOf...), s.AnyOf...)); len(variants) > 0 {
+		errs = append(errs, v.checkVariants(s, variants, val, name, field, in, depth)...)
+	}
+
+	if t := types(s); len(t) > 0 && !matchesAny(t, val) {
+		return append(errs, typeError(name, in, t[0]))
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, val) {
+		values := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			if e != nil {
+				values = append(values, fmt.Sprint(e))
+			}
+		}
+		errs = append(errs, FieldError{
+			Field:   name,
+			In:      in,
+			Rule:    "enum",
+			Param:   strings.Join(values, " "),
+			Message: "must be one of [" + strings.Join(values, ", ") + "]",
+		})
+	}
+
+	switch val := val.(type) {
+	case string:
+		errs = append(errs, v.checkString(s, val, name, in)...)
+	case float64:
+		errs = append(errs, checkNumber(s, val, name, in)...)
+	case []any:
+		errs = append(errs, checkCount(name, in, "Items", len(val), s.MinItems, s.MaxItems)...)
+		for i, item := range val {
+			errs = append(errs, v.checkDepth(s.Items, item, fmt.Sprintf("%s[%d]", name, i), in, depth+1)...)
+		}
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := val[req]; !ok {
+				errs = append(errs, required(join(field, req), in))
+			}
+		}
+		for _, key := range sortedKeys(val) {
+			if prop, ok := s.Properties[key]; ok {
+				errs = append(errs, v.checkDepth(prop, val[key], join(field, key), in, depth+1)...)
+			} else if s.AdditionalProperties != nil {
+				errs = append(errs, v.checkDepth(s.AdditionalProperties, val[key], join(field, key), in, depth+1)...)
+			}
+		}
+	}
+	return errs
+}
+
+// checkVariants 校验 oneOf/anyOf：有判别属性时按判别值选择分支，否则至少匹配一个分支。
+func (v *Validator) checkVariants(s *openapi.Schema, variants []*openapi.Schema, val any, name, field, in string, depth int) []FieldError {
+	if d := s.Discriminator; d != nil {
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return []FieldError{typeError(name, in, "object")}
+		}
+		key, _ := obj[d.PropertyName].(string)
+		if key == "" {
+			return []FieldError{required(join(field, d.PropertyName), in)}
+		}
+		ref := d.Mapping[key]
+		if ref == "" {
+			ref = "#/components/schemas/" + key
+		}
+		for _, variant := range variants {
+			if variant.Ref == ref {
+				return v.checkDepth(variant, val, field, in, depth+1)
+			}
+		}
+		return []FieldError{{
+			Field:   join(field, d.PropertyName),
+			In:      in,
+			Rule:    "discriminator",
+			Param:   key,
+			Message: fmt.Sprintf("unknown variant %q", key),
+		}}
+	}
+	for _, variant := range variants {
+		if len(v.checkDepth(variant, val, field, in, depth+1)) == 0 {
+			return nil
+		}
+	}
+	rule := "anyOf"
+	if len(s.OneOf) > 0 {
+		rule = "oneOf"
+	}
+	return []FieldError{{Field: name, In: in, Rule: rule, Message: "does not match any allowed schema"}}
+}
+
+func (v *Validator) checkString(s *openapi.Schema, val, name, in string) []FieldError {
+	errs := checkCount(name, in, "Length", utf8.RuneCountInString(val), s.MinLength, s.MaxLength)
+	if s.Pattern != "" {
+		if re := v.pattern(s.Pattern); re != nil && !re.MatchString(val) {
+			errs = append(errs, FieldError{Field: name, In: in, Rule: "pattern", Param: s.Pattern, Message: "must match pattern " + s.Pattern})
+		}
+	}
+	if s.Format != "" && !validFormat(s.Format, val) {
+		errs = append(errs, FieldError{Field: name, In: in, Rule: "format", Param: s.Format, Message: "must be a valid " + s.Format})
+	}
+	return errs
+}
+
+// pattern 编译并缓存正则，无法编译的正则（如 ECMA 专有语法）被忽略。
+func (v *Validator) pattern(p string) *regexp.Regexp {
+	if re, ok := v.patterns.Load(p); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil
+	}
+	v.patterns.Store(p, re)
+	return re
+}
+
+func checkNumber(s *openapi.Schema, val float64, name, in string) []FieldError {
+	var errs []FieldError
+	bound := func(rule string, limit float64, ok bool, op string) {
+		if !ok {
+			errs = append(errs, FieldError{
+				Field:   name,
+				In:      in,
+				Rule:    rule,
+				Param:   strconv.FormatFloat(limit, 'f', -1, 64),
+				Message: "must be " + op + " " + strconv.FormatFloat(limit, 'f', -1, 64),
+			})
+		}
+	}
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum {
+			bound("exclusiveMinimum", *s.Minimum, val > *s.Minimum, ">")
+		} else {
+			bound("minimum", *s.Minimum, val >= *s.Minimum, ">=")
+		}
+	}
+	if s.ExclusiveMinimumValue != nil {
+		bound("exclusiveMinimum", *s.ExclusiveMinimumValue, val > *s.ExclusiveMinimumValue, ">")
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum {
+			bound("exclusiveMaximum", *s.Maximum, val < *s.Maximum, "<")
+		} else {
+			bound("maximum", *s.Maximum, val <= *s.Maximum, "<=")
+		}
+	}
+	if s.ExclusiveMaximumValue != nil {
+		bound("exclusiveMaximum", *s.ExclusiveMaximumValue, val < *s.ExclusiveMaximumValue, "<")
+	}
+	return errs
+}
+
+// checkCount 校验长度或元素个数，kind 为 "Length" 或 "Items"。
+func checkCount(name, in, kind string, n int, min, max *int) []FieldError {
+	unit := "length"
+	if kind == "Items" {
+		unit = "item count"
+	}
+	var errs []FieldError
+	if min != nil && n < *min {
+		errs = append(errs, FieldError{Field: name, In: in, Rule: "min" + kind, Param: strconv.Itoa(*min), Message: fmt.Sprintf("%s must be at least %d", unit, *min)})
+	}
+	if max != nil && n > *max {
+		errs = append(errs, FieldError{Field: name, In: in, Rule: "max" + kind, Param: strconv.Itoa(*max), Message: fmt.Sprintf("%s must be at most %d", unit, *max)})
+	}
+	return errs
+}
+
+func validFormat(format, val string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(val)
+		return err == nil && addr.Address == val
+	case "uuid":
+		return uuidPattern.MatchString(val)
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, val)
+		return err == nil
+	case "uri", "url":
+		u, err := url.Parse(val)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(val)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(val)
+		return ip != nil && ip.To4() == nil
+	}
+	return true
+}
+
+// types 返回 schema 声明的非 null 类型，兼容 3.0 的 type 和 3.1 的类型数组。
+func types(s *openapi.Schema) []string {
+	if len(s.Types) > 0 {
+		out := make([]string, 0, len(s.Types))
+		for _, t := range s.Types {
+			if t != "null" {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	return nil
+}
+
+func schemaType(s *openapi.Schema) string {
+	if t := types(s); len(t) > 0 {
+		return t[0]
+	}
+	return ""
+}
+
+func isNullable(s *openapi.Schema) bool {
+	if s.Nullable {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == "null" {
+			return true
+		}
+	}
+	for _, sub := range s.AnyOf {
+		if sub != nil && sub.Type == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNull 过滤 3.1 可空表示中的 {type: null} 分支。
+func nonNull(schemas []*openapi.Schema) []*openapi.Schema {
+	out := schemas[:0:0]
+	for _, s := range schemas {
+		if s != nil && !(s.Type == "null" && s.Ref == "") {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func matchesAny(types []string, val any) bool {
+	for _, t := range types {
+		if matchesType(t, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(t string, val any) bool {
+	switch t {
+	case "object":
+		_, ok := val.(map[string]any)
+		return ok
+	case "array":
+		_, ok := val.([]any)
+		return ok
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "number":
+		_, ok := val.(float64)
+		return ok
+	case "integer":
+		n, ok := val.(float64)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	}
+	return true
+}
+
+// enumContains 比较枚举值，数字统一按 float64 比较。
+func enumContains(enum []any, val any) bool {
+	for _, e := range enum {
+		if e == nil {
+			continue
+		}
+		if n, ok := toFloat(e); ok {
+			if m, ok := val.(float64); ok && n == m {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+// Package validate 按构建好的 OpenAPI 文档在运行时校验 HTTP 请求，
+// 使文档中声明的参数、请求头和请求体约束对任意 handler 生效。
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+// multipartMemory 解析 multipart 表单时的内存上限，与 gin 默认值一致。
+const multipartMemory = 32 << 20
+
+// FieldError 字段级校验错误。
+type FieldError struct {
+	Field   string `json:"field"`           // 字段名，嵌套字段形如 "items[0].name"
+	In      string `json:"in"`              // 位置：path/query/header/cookie/body
+	Rule    string `json:"rule"`            // 失败的规则，如 required、minimum、enum
+	Param   string `json:"param,omitempty"` // 规则参数，如 minimum 的边界值
+	Message string `json:"message"`         // 可读的错误描述
+}
+
+// Validator 按 OpenAPI 文档校验请求，可被多个 goroutine 并发使用。
+type Validator struct {
+	doc      *openapi.Document
+	patterns sync.Map // pattern -> *regexp.Regexp
+}
+
+// New 基于已构建的文档创建 Validator。
+func New(doc *openapi.Document) *Validator {
+	return &Validator{doc: doc}
+}
+
+// Operation 返回 method + path（OpenAPI 风格）对应的操作，不存在时返回 nil。
+func (v *Validator) Operation(method, path string) *openapi.OperationObject {
+	if v.doc == nil {
+		return nil
+	}
+	item := v.doc.Paths[path]
+	if item == nil {
+		return nil
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodHead:
+		return item.Head
+	case http.MethodOptions:
+		return item.Options
+	}
+	return nil
+}
+
+// Request 校验请求的参数、请求头、Cookie 和请求体。path 为 OpenAPI 风格路径，
+// pathParams 为路由解析出的路径参数。文档中没有对应操作时 ok 为 false。
+// 读取的请求体会被还原，后续 handler 仍可正常绑定。
+func (v *Validator) Request(r *http.Request, path string, pathParams map[string]string) (errs []FieldError, ok bool) {
+	op := v.Operation(r.Method, path)
+	if op == nil {
+		return nil, false
+	}
+	for _, p := range op.Parameters {
+		if p == nil {
+			continue
+		}
+		// 跳过路径模板中不存在的路径参数
+		if p.In == "path" && !strings.Contains(path, "{"+p.Name+"}") {
+			continue
+		}
+		errs = append(errs, v.param(r, p, pathParams)...)
+	}
+	if op.RequestBody != nil {
+		errs = append(errs, v.body(r, op.RequestBody)...)
+	}
+	return errs, true
+}
+
+// param 校验单个参数。
+func (v *Validator) param(r *http.Request, p *openapi.Parameter, pathParams map[string]string) []FieldError {
+	var raw []string
+	switch p.In {
+	case "path":
+		if val, ok := pathParams[p.Name]; ok {
+			raw = []string{val}
+		}
+	case "query":
+		raw = r.URL.Query()[p.Name]
+	case "header":
+		raw = r.Header.Values(p.Name)
+	case "cookie":
+		if c, err := r.Cookie(p.Name); err == nil {
+			raw = []string{c.Value}
+		}
+	}
+	if len(raw) == 0 || (len(raw) == 1 && raw[0] == "") {
+		if p.Required {
+			return []FieldError{required(p.Name, p.In)}
+		}
+		return nil
+	}
+	return v.parse(p.Schema, raw, p.Name, p.In)
+}
+
+// parse 将字符串形式的值按 schema 转换后校验，用于参数和表单字段。
+func (v *Validator) parse(s *openapi.Schema, raw []string, field, in string) []FieldError {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+	if schemaType(s) == "array" {
+		// 查询参数和表单用重复键传递数组，路径和请求头用逗号分隔
+		if len(raw) == 1 && (in == "path" || in == "header") {
+			raw = strings.Split(raw[0], ",")
+		}
+		items := make([]any, 0, len(raw))
+		for i, str := range raw {
+			val, err := v.coerce(s.Items, str, fmt.Sprintf("%s[%d]", field, i), in)
+			if err != nil {
+				return []FieldError{*err}
+			}
+			items = append(items, val)
+		}
+		return v.check(s, items, field, in)
+	}
+	val, err := v.coerce(s, raw[0], field, in)
+	if err != nil {
+		return []FieldError{*err}
+	}
+	return v.check(s, val, field, in)
+}
+
+// coerce 按 schema 类型将字符串转换为 JSON 值。
+func (v *Validator) coerce(s *openapi.Schema, str, field, in string) (any, *FieldError) {
+	s = v.resolve(s)
+	if s == nil {
+		return str, nil
+	}
+	switch t := schemaType(s); t {
+	case "integer":
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			e := typeError(field, in, t)
+			return nil, &e
+		}
+		return float64(n), nil
+	case "number":
+		n, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			e := typeError(field, in, t)
+			return nil, &e
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			e := typeError(field, in, t)
+			return nil, &e
+		}
+		return b, nil
+	}
+	return str, nil
+}
+
+// body 校验请求体。
+func (v *Validator) body(r *http.Request, rb *openapi.RequestBody) []FieldError {
+	if r.Body == nil || r.Body == http.NoBody {
+		if rb.Required {
+			return []FieldError{required("body", "body")}
+		}
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+	media, ok := rb.Content[mediaType]
+	if !ok {
+		// 请求为空且非必填时不检查内容类型
+		if !rb.Required && r.ContentLength == 0 {
+			return nil
+		}
+		return []FieldError{{
+			Field:   "body",
+			In:      "body",
+			Rule:    "contentType",
+			Param:   mediaType,
+			Message: fmt.Sprintf("unsupported content type %q", mediaType),
+		}}
+	}
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+
+	switch {
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(multipartMemory); err != nil {
+			return []FieldError{invalidBody(err)}
+		}
+		return v.form(media.Schema, r.MultipartForm.Value, r.MultipartForm.File, rb.Required)
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return []FieldError{invalidBody(err)}
+		}
+		return v.form(media.Schema, r.PostForm, nil, rb.Required)
+	case strings.HasSuffix(mediaType, "json"):
+		data, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			return []FieldError{invalidBody(err)}
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			if rb.Required {
+				return []FieldError{required("body", "body")}
+			}
+			return nil
+		}
+		var val any
+		if err := json.Unmarshal(data, &val); err != nil {
+			return []FieldError{invalidBody(err)}
+		}
+		return v.check(media.Schema, val, "", "body")
+	}
+	return nil
+}
+
+// form 校验表单请求体，文件字段只检查是否存在。
+func (v *Validator) form(s *openapi.Schema, values url.Values, files map[string][]*multipart.FileHeader, bodyRequired bool) []FieldError {
+	props, req := v.properties(s, nil)
+	if len(values) == 0 && len(files) == 0 && !bodyRequired {
+		return nil
+	}
+
+	var errs []FieldError
+	for _, name := range sortedKeys(props) {
+		prop := v.resolve(props[name])
+		if isBinary(prop) || (prop != nil && schemaType(prop) == "array" && isBinary(v.resolve(prop.Items))) {
+			if len(files[name]) == 0 && contains(req, name) {
+				errs = append(errs, required(name, "body"))
+			}
+			continue
+		}
+		raw := values[name]
+		if len(raw) == 0 || (len(raw) == 1 && raw[0] == "") {
+			if contains(req, name) {
+				errs = append(errs, required(name, "body"))
+			}
+			continue
+		}
+		errs = append(errs, v.parse(prop, raw, name, "body")...)
+	}
+	return errs
+}
+
+// properties 汇总 schema（含 $ref 和 allOf）声明的属性和必填列表。
+func (v *Validator) properties(s *openapi.Schema, seen map[string]bool) (map[string]*openapi.Schema, []string) {
+	props := make(map[string]*openapi.Schema)
+	var req []string
+	if s == nil {
+		return props, nil
+	}
+	if s.Ref != "" {
+		if seen == nil {
+			seen = make(map[string]bool)
+		}
+		if seen[s.Ref] {
+			return props, nil
+		}
+		seen[s.Ref] = true
+	}
+	s = v.resolve(s)
+	if s == nil {
+		return props, nil
+	}
+	for _, sub := range s.AllOf {
+		p, r := v.properties(sub, seen)
+		for k, val := range p {
+			props[k] = val
+		}
+		req = append(req, r...)
+	}
+	for k, val := range s.Properties {
+		props[k] = val
+	}
+	return props, append(req, s.Required...)
+}
+
+// resolve 解析 $ref，循环或缺失的引用返回 nil。
+func (v *Validator) resolve(s *openapi.Schema) *openapi.Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if v.doc == nil || v.doc.Components.Schemas == nil {
+			return nil
+		}
+		s = v.doc.Components.Schemas[name]
+	}
+	if s != nil && s.Ref != "" {
+		return nil
+	}
+	return s
+}
+
+func required(field, in string) FieldError {
+	return FieldError{Field: field, In: in, Rule: "required", Message: "is required"}
+}
+
+func typeError(field, in, typ string) FieldError {
+	return FieldError{Field: field, In: in, Rule: "type", Param: typ, Message: "must be of type " + typ}
+}
+
+func invalidBody(err error) FieldError {
+	return FieldError{Field: "body", In: "body", Rule: "format", Message: "invalid request body: " + err.Error()}
+}
+
+func isBinary(s *openapi.Schema) bool {
+	return s != nil && (s.Format == "binary" || s.ContentMediaType != "")
+}
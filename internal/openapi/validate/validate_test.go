@@ -0,0 +1,167 @@
+package validate
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tokmz/qi/internal/openapi"
+)
+
+type pathReq struct {
+	ID int64 `uri:"id" binding:"gt=0"`
+}
+
+type uploadForm struct {
+	Title string                `form:"title" binding:"required,max=8"`
+	File  *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius" binding:"gt=0"`
+}
+
+func (circle) area() float64 { return 0 }
+
+type square struct {
+	Kind string  `json:"kind"`
+	Side float64 `json:"side"`
+}
+
+func (square) area() float64 { return 0 }
+
+type drawReq struct {
+	Shape shape   `json:"shape" binding:"required"`
+	Note  *string `json:"note"`
+}
+
+func newValidator(t *testing.T, version string) *Validator {
+	t.Helper()
+	m := openapi.New(
+		openapi.WithOpenAPIVersion(version),
+		openapi.WithUnion(openapi.OneOf[shape]("kind",
+			openapi.Variant("circle", circle{}),
+			openapi.Variant("square", square{}),
+		)),
+	)
+	m.MustAddOperations(
+		openapi.Operation{Method: "GET", Path: "/items/{id}", Request: &openapi.Request{PathParams: pathReq{}}},
+		openapi.Operation{
+			Method:  "POST",
+			Path:    "/uploads",
+			Request: &openapi.Request{Body: uploadForm{}, BodyRequired: true, BodyContentType: "multipart/form-data"},
+		},
+		openapi.Operation{Method: "POST", Path: "/draw", Request: &openapi.Request{Body: drawReq{}, BodyRequired: true}},
+	)
+	doc, err := m.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return New(doc)
+}
+
+func rules(errs []FieldError) map[string]string {
+	out := make(map[string]string, len(errs))
+	for _, e := range errs {
+		out[e.Field] = e.Rule
+	}
+	return out
+}
+
+func TestRequestPathParams(t *testing.T) {
+	for _, version := range []string{openapi.Version30, openapi.Version31} {
+		v := newValidator(t, version)
+		r := httptest.NewRequest("GET", "/items/x", nil)
+		errs, ok := v.Request(r, "/items/{id}", map[string]string{"id": "x"})
+		if !ok || rules(errs)["id"] != "type" {
+			t.Errorf("%s: non-integer id: ok = %v, errs = %v", version, ok, errs)
+		}
+		errs, _ = v.Request(r, "/items/{id}", map[string]string{"id": "0"})
+		if rules(errs)["id"] != "exclusiveMinimum" {
+			t.Errorf("%s: id=0: errs = %v", version, errs)
+		}
+		if errs, _ := v.Request(r, "/items/{id}", map[string]string{"id": "7"}); len(errs) != 0 {
+			t.Errorf("%s: id=7: errs = %v", version, errs)
+		}
+	}
+
+	v := newValidator(t, openapi.Version30)
+	if _, ok := v.Request(httptest.NewRequest("GET", "/missing", nil), "/missing", nil); ok {
+		t.Error("undocumented operation should not be validated")
+	}
+}
+
+func TestRequestMultipart(t *testing.T) {
+	v := newValidator(t, openapi.Version30)
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("title", "a very long title")
+	w.Close()
+	r := httptest.NewRequest("POST", "/uploads", &buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	errs, _ := v.Request(r, "/uploads", nil)
+	if got := rules(errs); got["title"] != "maxLength" || got["file"] != "required" {
+		t.Errorf("errs = %v", errs)
+	}
+
+	r = httptest.NewRequest("POST", "/uploads", strings.NewReader("title=x"))
+	r.Header.Set("Content-Type", "text/plain")
+	errs, _ = v.Request(r, "/uploads", nil)
+	if rules(errs)["body"] != "contentType" {
+		t.Errorf("unsupported content type: errs = %v", errs)
+	}
+}
+
+func TestRequestDiscriminator(t *testing.T) {
+	for _, version := range []string{openapi.Version30, openapi.Version31} {
+		v := newValidator(t, version)
+		tests := []struct {
+			body string
+			want map[string]string
+		}{
+			{`{"shape":{"kind":"circle","radius":2},"note":null}`, map[string]string{}},
+			{`{"shape":{"kind":"circle","radius":0}}`, map[string]string{"shape.radius": "exclusiveMinimum"}},
+			{`{"shape":{"kind":"hexagon"}}`, map[string]string{"shape.kind": "discriminator"}},
+			{`{"shape":{"kind":"square","side":"x"}}`, map[string]string{"shape.side": "type"}},
+			{`{}`, map[string]string{"shape": "required"}},
+			{`{"shape":`, map[string]string{"body": "format"}},
+		}
+		for _, tt := range tests {
+			r := httptest.NewRequest("POST", "/draw", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			errs, _ := v.Request(r, "/draw", nil)
+			got := rules(errs)
+			if len(got) != len(tt.want) {
+				t.Errorf("%s %s: errs = %v, want %v", version, tt.body, errs, tt.want)
+				continue
+			}
+			for k, rule := range tt.want {
+				if got[k] != rule {
+					t.Errorf("%s %s: errs[%s] = %q, want %q", version, tt.body, k, got[k], rule)
+				}
+			}
+		}
+	}
+}
+
+func TestRequestRestoresBody(t *testing.T) {
+	v := newValidator(t, openapi.Version30)
+	body := `{"shape":{"kind":"square","side":1}}`
+	r := httptest.NewRequest("POST", "/draw", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if errs, _ := v.Request(r, "/draw", nil); len(errs) != 0 {
+		t.Fatalf("errs = %v", errs)
+	}
+	var buf bytes.Buffer
+	buf.ReadFrom(r.Body)
+	if buf.String() != body {
+		t.Errorf("body after validation = %q, want %q", buf.String(), body)
+	}
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的 OpenAPI 文档版本。
+const (
+	Version30 = "3.0.3"
+	Version31 = "3.1.0"
+)
+
+// JSONSchemaDialect31 是 3.1 文档默认的 JSON Schema 方言（基于 2020-12）。
+const JSONSchemaDialect31 = "https://spec.openapis.org/oas/3.1/dialect/base"
+
+// checkOpenAPIVersion 校验目标版本，返回是否为 3.1。
+func checkOpenAPIVersion(v string) (bool, error) {
+	switch {
+	case strings.HasPrefix(v, "3.0."):
+		return false, nil
+	case strings.HasPrefix(v, "3.1."):
+		return true, nil
+	default:
+		return false, fmt.Errorf("openapi: unsupported OpenAPI version %q", v)
+	}
+}
+
+// upgradeDocument31 将以 3.0 结构构建的文档原地转换为 3.1 表达。
+func upgradeDocument31(doc *Document) {
+	doc.JSONSchemaDialect = JSONSchemaDialect31
+	for _, schema := range doc.Components.Schemas {
+		upgradeSchema31(schema)
+	}
+	for _, item := range doc.Paths {
+		for _, op := range []*OperationObject{item.Get, item.Post, item.Put, item.Delete, item.Patch, item.Head, item.Options} {
+			if op == nil {
+				continue
+			}
+			for _, param := range op.Parameters {
+				upgradeSchema31(param.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					upgradeSchema31(media.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					upgradeSchema31(media.Schema)
+				}
+			}
+		}
+	}
+}
+
+// upgradeSchema31 递归转换单个 schema：
+//   - nullable → type 数组追加 "null"；$ref 无法携带类型时改为 anyOf[$ref, {type: null}]
+//   - 布尔 exclusiveMinimum/Maximum → 数值边界，并移除对应 minimum/maximum
+//   - example → examples 数组
+//   - format: binary → contentMediaType: application/octet-stream
+func upgradeSchema31(s *Schema) {
+	if s == nil {
+		return
+	}
+
+	// 3.0 下可空引用表示为 allOf[$ref, {nullable: true, ...}]，3.1 允许 $ref 旁挂关键字，
+	// 将元信息提升到外层并以 anyOf 表达可空。
+	if len(s.AllOf) == 2 && s.AllOf[0].Ref != "" && s.AllOf[1].Nullable && s.AllOf[1].Type == "" {
+		ref, meta := s.AllOf[0], s.AllOf[1]
+		meta.Nullable = false
+		meta.AnyOf = []*Schema{ref, {Type: "null"}}
+		*s = *meta
+	}
+
+	for _, child := range s.AllOf {
+		upgradeSchema31(child)
+	}
+	for _, child := range s.OneOf {
+		upgradeSchema31(child)
+	}
+	for _, child := range s.AnyOf {
+		upgradeSchema31(child)
+	}
+	for _, child := range s.Properties {
+		upgradeSchema31(child)
+	}
+	upgradeSchema31(s.Items)
+	upgradeSchema31(s.AdditionalProperties)
+
+	if s.Nullable {
+		s.Nullable = false
+		if s.Type != "" {
+			s.Types = []string{s.Type, "null"}
+			s.Type = ""
+			if len(s.Enum) > 0 && !containsNil(s.Enum) {
+				s.Enum = append(s.Enum, nil)
+			}
+		}
+	}
+	if s.ExclusiveMinimum {
+		s.ExclusiveMinimum = false
+		s.ExclusiveMinimumValue, s.Minimum = s.Minimum, nil
+	}
+	if s.ExclusiveMaximum {
+		s.ExclusiveMaximum = false
+		s.ExclusiveMaximumValue, s.Maximum = s.Maximum, nil
+	}
+	if s.Example != nil {
+		s.Examples = []any{s.Example}
+		s.Example = nil
+	}
+	if s.Type == "string" && s.Format == "binary" {
+		s.Format = ""
+		s.ContentMediaType = "application/octet-stream"
+	}
+}
+
+func containsNil(values []any) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
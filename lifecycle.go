@@ -0,0 +1,197 @@
+package qi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Component 由 Engine 管理生命周期的组件（数据库、缓存、消息队列、日志等）。
+// Run 在 HTTP 服务监听之前按依赖顺序启动组件，关闭时按启动的相反顺序关闭。
+type Component struct {
+	Name        string                          // 组件名，唯一
+	DependsOn   []string                        // 依赖的组件名，依赖先启动、后关闭
+	Start       func(ctx context.Context) error // 启动函数，可为 nil
+	Stop        func(ctx context.Context) error // 关闭函数，可为 nil
+	StopTimeout time.Duration                   // 关闭超时时间，0 时使用 Config.StopTimeout，不超过 ShutdownTimeout 的剩余时间
+}
+
+// Closer 将 Close() error 适配为 Component.Stop，适用于 cache.Cache、mq.Producer、logger.Logger 等。
+//
+// 示例：
+//
+//	e.Register(qi.Component{Name: "cache", Stop: qi.Closer(c)})
+func Closer(c interface{ Close() error }) func(context.Context) error {
+	return func(context.Context) error {
+		return c.Close()
+	}
+}
+
+// Register 注册组件，组件名为空或重复时 panic。应在 Run 之前调用。
+//
+// 示例：
+//
+//	db, _ := database.New(dbCfg)
+//	sqlDB, _ := db.DB()
+//	e.Register(qi.Component{Name: "db", Stop: qi.Closer(sqlDB)})
+//	e.Register(qi.Component{Name: "cache", DependsOn: []string{"db"}, Stop: qi.Closer(c)})
+//	e.Register(qi.Component{Name: "logger", Stop: qi.Closer(log)})
+func (e *Engine) Register(c Component) {
+	if c.Name == "" {
+		panic("qi: component name is required")
+	}
+	for _, exist := range e.lifecycle.components {
+		if exist.Name == c.Name {
+			panic("qi: duplicate component " + c.Name)
+		}
+	}
+	e.lifecycle.components = append(e.lifecycle.components, c)
+}
+
+// OnStart 注册启动钩子，在所有组件启动之后、HTTP 服务监听之前按注册顺序执行。
+// 钩子返回错误或超过 Config.StartTimeout 时 Run 关闭已启动的组件并返回该错误。
+func (e *Engine) OnStart(fn func(ctx context.Context) error) {
+	e.lifecycle.onStart = append(e.lifecycle.onStart, fn)
+}
+
+// OnShutdown 注册关闭钩子，在 HTTP 服务关闭之后、组件关闭之前按注册的相反顺序执行。
+// 单个钩子受 Config.StopTimeout 限制，需要单独的超时时间时使用 Register 注册只含 Stop 的组件。
+func (e *Engine) OnShutdown(fn func(ctx context.Context) error) {
+	e.lifecycle.onShutdown = append(e.lifecycle.onShutdown, fn)
+}
+
+// lifecycle 组件注册表和生命周期钩子。
+type lifecycle struct {
+	components []Component
+	onStart    []func(ctx context.Context) error
+	onShutdown []func(ctx context.Context) error
+	started    []Component // 已启动的组件，按启动顺序
+
+	startTimeout time.Duration // 单个启动钩子的超时时间
+	stopTimeout  time.Duration // 单个关闭钩子的默认超时时间
+}
+
+// start 按依赖顺序启动组件并执行启动钩子，失败时在 stopCtx 内关闭已启动的组件。
+func (l *lifecycle) start(ctx context.Context, stopCtx func() (context.Context, context.CancelFunc)) error {
+	order, err := l.order()
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		ctx, cancel := stopCtx()
+		defer cancel()
+		return errors.Join(err, l.stopComponents(ctx))
+	}
+	for _, c := range order {
+		if c.Start != nil {
+			if err := runHook(ctx, l.startTimeout, c.Start); err != nil {
+				return fail(fmt.Errorf("qi: start %s: %w", c.Name, err))
+			}
+		}
+		l.started = append(l.started, c)
+	}
+	for i, fn := range l.onStart {
+		if err := runHook(ctx, l.startTimeout, fn); err != nil {
+			return fail(fmt.Errorf("qi: start hook %d: %w", i+1, err))
+		}
+	}
+	return nil
+}
+
+// stop 按注册的相反顺序执行关闭钩子，再按启动的相反顺序关闭组件，汇总所有错误。
+func (l *lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for i := len(l.onShutdown) - 1; i >= 0; i-- {
+		if err := runHook(ctx, l.stopTimeout, l.onShutdown[i]); err != nil {
+			errs = append(errs, fmt.Errorf("qi: shutdown hook %d: %w", i+1, err))
+		}
+	}
+	return errors.Join(append(errs, l.stopComponents(ctx))...)
+}
+
+// stopComponents 按启动的相反顺序关闭已启动的组件。
+func (l *lifecycle) stopComponents(ctx context.Context) error {
+	var errs []error
+	for i := len(l.started) - 1; i >= 0; i-- {
+		c := l.started[i]
+		if c.Stop == nil {
+			continue
+		}
+		timeout := c.StopTimeout
+		if timeout == 0 {
+			timeout = l.stopTimeout
+		}
+		if err := runHook(ctx, timeout, c.Stop); err != nil {
+			errs = append(errs, fmt.Errorf("qi: stop %s: %w", c.Name, err))
+		}
+	}
+	l.started = nil
+	return errors.Join(errs...)
+}
+
+// order 按依赖关系排序组件，无依赖关系的组件保持注册顺序；依赖不存在或循环依赖时返回错误。
+func (l *lifecycle) order() ([]Component, error) {
+	byName := make(map[string]Component, len(l.components))
+	for _, c := range l.components {
+		byName[c.Name] = c
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(l.components))
+	out := make([]Component, 0, len(l.components))
+	var visit func(c Component, path []string) error
+	visit = func(c Component, path []string) error {
+		switch state[c.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("qi: component dependency cycle: %v", append(path, c.Name))
+		}
+		state[c.Name] = visiting
+		for _, dep := range c.DependsOn {
+			d, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("qi: component %s depends on unknown component %s", c.Name, dep)
+			}
+			if err := visit(d, append(path, c.Name)); err != nil {
+				return err
+			}
+		}
+		state[c.Name] = visited
+		out = append(out, c)
+		return nil
+	}
+	for _, c := range l.components {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// runHook 执行钩子，timeout > 0 时限制单次执行时间，不超过 ctx 的剩余时间；ctx 结束时不再等待钩子返回。
+func runHook(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,243 @@
+package qi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEngine_Lifecycle(t *testing.T) {
+	e := New(WithMode("test"))
+	var events []string
+	component := func(name string, deps ...string) Component {
+		return Component{
+			Name:      name,
+			DependsOn: deps,
+			Start: func(context.Context) error {
+				events = append(events, "start "+name)
+				return nil
+			},
+			Stop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return nil
+			},
+		}
+	}
+	e.Register(component("cache", "db", "logger"))
+	e.Register(component("logger"))
+	e.Register(component("db", "logger"))
+	e.Register(component("mq"))
+	e.OnStart(func(context.Context) error {
+		events = append(events, "on start")
+		return nil
+	})
+	e.OnShutdown(func(context.Context) error {
+		events = append(events, "on shutdown 1")
+		return nil
+	})
+	e.OnShutdown(func(context.Context) error {
+		events = append(events, "on shutdown 2")
+		return nil
+	})
+
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"start logger", "start db", "start cache", "start mq", "on start",
+		"on shutdown 2", "on shutdown 1",
+		"stop mq", "stop cache", "stop db", "stop logger",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v\nwant %v", events, want)
+	}
+}
+
+func TestEngine_LifecycleStartFailure(t *testing.T) {
+	e := New(WithMode("test"))
+	var stopped []string
+	e.Register(Component{Name: "db", Stop: func(context.Context) error {
+		stopped = append(stopped, "db")
+		return nil
+	}})
+	e.Register(Component{Name: "mq", Start: func(context.Context) error {
+		return errors.New("broker unreachable")
+	}, Stop: func(context.Context) error {
+		stopped = append(stopped, "mq")
+		return nil
+	}})
+
+	err := e.lifecycle.start(context.Background(), e.shutdownContext)
+	if err == nil || !strings.Contains(err.Error(), "qi: start mq: broker unreachable") {
+		t.Fatalf("start error = %v", err)
+	}
+	// 只关闭已启动的组件
+	if !reflect.DeepEqual(stopped, []string{"db"}) {
+		t.Errorf("stopped = %v, want [db]", stopped)
+	}
+}
+
+func TestEngine_LifecycleShutdownErrors(t *testing.T) {
+	e := New(WithMode("test"), func(c *Config) { c.ShutdownTimeout = time.Second })
+	errCache := errors.New("cache close failed")
+	e.Register(Component{Name: "cache", Stop: Closer(closerFunc(func() error { return errCache }))})
+	e.Register(Component{Name: "slow", StopTimeout: 20 * time.Millisecond, Stop: func(ctx context.Context) error {
+		time.Sleep(time.Second) // 忽略 ctx 的组件不会拖住关闭流程
+		return nil
+	}})
+	var logged bool
+	e.Register(Component{Name: "logger", Stop: func(context.Context) error {
+		logged = true
+		return nil
+	}})
+
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	err := e.shutdown()
+	if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+		t.Errorf("shutdown took %v, want bounded by StopTimeout", elapsed)
+	}
+	if !errors.Is(err, errCache) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown error = %v, want cache error and slow timeout", err)
+	}
+	if !logged {
+		t.Error("logger not stopped after earlier failures")
+	}
+}
+
+func TestEngine_LifecycleHookTimeouts(t *testing.T) {
+	e := New(WithMode("test"), func(c *Config) {
+		c.ShutdownTimeout = 300 * time.Millisecond
+		c.StartTimeout = 20 * time.Millisecond
+		c.StopTimeout = 20 * time.Millisecond
+	})
+	release := make(chan struct{})
+	defer close(release)
+	var closed []string
+	e.Register(Component{Name: "db", Stop: func(context.Context) error {
+		closed = append(closed, "db")
+		return nil
+	}})
+	e.OnStart(func(context.Context) error {
+		<-release // 忽略 ctx 的启动钩子受 StartTimeout 限制
+		return nil
+	})
+	err := e.lifecycle.start(context.Background(), e.shutdownContext)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "qi: start hook 1") {
+		t.Fatalf("start error = %v", err)
+	}
+	if !reflect.DeepEqual(closed, []string{"db"}) {
+		t.Errorf("closed after start failure = %v", closed)
+	}
+
+	e.lifecycle.onStart = nil
+	closed = nil
+	e.OnShutdown(func(context.Context) error {
+		<-release // 单个关闭钩子受 StopTimeout 限制，不会占满 ShutdownTimeout
+		return nil
+	})
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	err = e.shutdown()
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "qi: shutdown hook 1") {
+		t.Errorf("shutdown error = %v", err)
+	}
+	if !reflect.DeepEqual(closed, []string{"db"}) {
+		t.Errorf("closed = %v, want [db]", closed)
+	}
+
+	// 组件的 StopTimeout 不超过 ShutdownTimeout 的剩余时间
+	e = New(WithMode("test"), func(c *Config) { c.ShutdownTimeout = 100 * time.Millisecond })
+	e.Register(Component{Name: "cache", StopTimeout: time.Hour, Stop: func(context.Context) error {
+		<-release
+		return nil
+	}})
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	err = e.shutdown()
+	if elapsed := time.Since(begin); elapsed > 300*time.Millisecond {
+		t.Errorf("shutdown took %v, want bounded by ShutdownTimeout", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "qi: stop cache") {
+		t.Errorf("shutdown error = %v", err)
+	}
+}
+
+func TestEngine_LifecycleSlowHTTPShutdown(t *testing.T) {
+	e := New(WithMode("test"), func(c *Config) {
+		c.ShutdownTimeout = 200 * time.Millisecond
+		c.ShutdownReserve = 80 * time.Millisecond
+	})
+	entered, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	e.GET("/slow", func(c *Context) {
+		close(entered)
+		<-release
+	})
+	var closed atomic.Bool
+	e.Register(Component{Name: "db", Stop: func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		closed.Store(true)
+		return ctx.Err()
+	}})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go e.server.Serve(ln)
+	go http.Get("http://" + ln.Addr().String() + "/slow")
+	<-entered
+
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	// 慢请求耗尽 HTTP 服务的关闭时间后，组件仍在 ShutdownReserve 内关闭
+	err = e.shutdown()
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "qi: http server shutdown") {
+		t.Errorf("shutdown error = %v, want http server timeout", err)
+	}
+	if strings.Contains(err.Error(), "qi: stop") || !closed.Load() {
+		t.Errorf("db not stopped cleanly: %v", err)
+	}
+}
+
+func TestEngine_LifecycleDependencyErrors(t *testing.T) {
+	e := New(WithMode("test"))
+	e.Register(Component{Name: "a", DependsOn: []string{"b"}})
+	e.Register(Component{Name: "b", DependsOn: []string{"a"}})
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("cycle error = %v", err)
+	}
+
+	e = New(WithMode("test"))
+	e.Register(Component{Name: "cache", DependsOn: []string{"redis"}})
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err == nil || !strings.Contains(err.Error(), "unknown component redis") {
+		t.Errorf("unknown dependency error = %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate component should panic")
+		}
+	}()
+	e.Register(Component{Name: "cache"})
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
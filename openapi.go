@@ -1,11 +1,22 @@
 package qi
 
 import (
+	"fmt"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/gin-gonic/gin"
 	"github.com/tokmz/qi/internal/openapi"
+	"github.com/tokmz/qi/internal/openapi/clientgen"
+	"github.com/tokmz/qi/pkg/errors"
 )
 
 // ===== 辅助函数 =====
@@ -62,6 +73,36 @@ func buildPathParamType(params []string) any {
 	return reflect.New(t).Elem().Interface()
 }
 
+// fileHeaderType 是上传文件字段类型 multipart.FileHeader。
+var fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+// typeHasFileField 扫描结构体字段（含嵌入）检查是否有 *multipart.FileHeader 或 []*multipart.FileHeader 字段。
+func typeHasFileField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft == fileHeaderType {
+			return true
+		}
+		if field.Anonymous && typeHasFileField(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
 // isBodyMethod 判断 HTTP 方法是否应将 Request 数据作为 Body 处理。
 func isBodyMethod(method string) bool {
 	switch strings.ToUpper(method) {
@@ -72,6 +113,52 @@ func isBodyMethod(method string) bool {
 	}
 }
 
+// isFormContentType 判断请求体是否为表单（multipart/form-data、application/x-www-form-urlencoded）。
+func isFormContentType(contentType string) bool {
+	switch contentType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		return true
+	default:
+		return false
+	}
+}
+
+// typeHasBodyField 扫描结构体字段（含嵌入）检查是否有绑定自请求体的字段：
+// 带 json tag，或没有 uri/header/cookie tag 且（表单请求体时）带 form tag 或（非表单时）没有 form tag。
+func typeHasBodyField(t reflect.Type, formBody bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if typeHasBodyField(field.Type, formBody) {
+				return true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+			if name != "-" {
+				return true
+			}
+			continue
+		}
+		if field.Tag.Get("uri") != "" || field.Tag.Get("header") != "" || field.Tag.Get("cookie") != "" {
+			continue
+		}
+		if formBody || field.Tag.Get("form") == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // ===== OpenAPIConfig =====
 
 // OpenAPIOption 是 OpenAPI 配置选项类型，包装 internal/openapi.Option。
@@ -80,14 +167,115 @@ type OpenAPIOption = openapi.Option
 // Server 是 OpenAPI 服务器信息，透传自 internal/openapi.Server。
 type Server = openapi.Server
 
+// OpenAPI 文档版本，用于 OpenAPIConfig.OpenAPIVersion。
+const (
+	OpenAPIVersion30 = openapi.Version30
+	OpenAPIVersion31 = openapi.Version31
+)
+
+// SecurityScheme 是 OpenAPI 安全方案，透传自 internal/openapi.SecurityScheme。
+type SecurityScheme = openapi.SecurityScheme
+
+// OAuthFlows 是 OAuth2 授权流程集合，透传自 internal/openapi.OAuthFlows。
+type OAuthFlows = openapi.OAuthFlows
+
+// OAuthFlow 是单个 OAuth2 授权流程，透传自 internal/openapi.OAuthFlow。
+type OAuthFlow = openapi.OAuthFlow
+
+// Union 是接口类型的多态描述，透传自 internal/openapi.Union。
+type Union = openapi.Union
+
+// UnionVariant 是 Union 的一个实现，透传自 internal/openapi.UnionVariant。
+type UnionVariant = openapi.UnionVariant
+
+// Variant 创建判别值为 value 的实现，sample 为实现类型样例。
+func Variant(value string, sample any) UnionVariant {
+	return openapi.Variant(value, sample)
+}
+
+// OneOf 声明接口 T 的实现集合，文档中生成 oneOf 和 discriminator（discriminator 为空时不生成）。
+//
+//	qi.OneOf[Event]("type", qi.Variant("user.created", UserCreated{}), qi.Variant("order.paid", OrderPaid{}))
+func OneOf[T any](discriminator string, variants ...UnionVariant) Union {
+	return openapi.OneOf[T](discriminator, variants...)
+}
+
+// AnyOf 声明接口 T 的实现集合，文档中生成 anyOf。
+func AnyOf[T any](samples ...any) Union {
+	return openapi.AnyOf[T](samples...)
+}
+
+// BearerAuth 返回 HTTP Bearer 安全方案，format 为令牌格式提示（如 "JWT"），可为空。
+func BearerAuth(format string) *SecurityScheme {
+	return &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: format}
+}
+
+// APIKeyAuth 返回 API Key 安全方案，in 取值 header/query/cookie，name 为参数名。
+func APIKeyAuth(in, name string) *SecurityScheme {
+	return &SecurityScheme{Type: "apiKey", In: in, Name: name}
+}
+
+// OAuth2Auth 返回 OAuth2 安全方案。
+func OAuth2Auth(flows *OAuthFlows) *SecurityScheme {
+	return &SecurityScheme{Type: "oauth2", Flows: flows}
+}
+
 // OpenAPIConfig 定义 OpenAPI 文档的配置。
 type OpenAPIConfig struct {
-	Title       string           // API 标题，默认 "OpenAPI"
-	Version     string           // API 版本号，默认 "1.0.0"
-	Description string           // API 描述
-	Path        string           // spec 端点路径，默认 "/openapi.json"
-	SwaggerUI   string           // Swagger UI 路径，空字符串=不注册
-	Servers     []Server           // 服务器列表
+	Title       string   // API 标题，默认 "OpenAPI"
+	Version     string   // API 版本号，默认 "1.0.0"
+	Description string   // API 描述
+	Path        string   // spec 端点路径，默认 "/openapi.json"
+	SwaggerUI   string   // Swagger UI 路径，空字符串=不注册
+	Servers     []Server // 服务器列表
+	YAMLPath    string   // YAML spec 端点路径，默认由 Path 推导（/openapi.yaml）
+
+	// OpenAPIVersion 输出的文档版本，默认 "3.0.3"；设为 "3.1.0" 时按 3.1 / JSON Schema 2020-12 输出。
+	OpenAPIVersion string
+
+	// Documents 命名子文档，按标签或路径前缀过滤路由，
+	// 端点为 {Path 去扩展名}/{Name}.json 和 .yaml，如 /openapi/public.json。
+	Documents []OpenAPIDocument
+
+	// DisableResponseEnvelope 关闭统一响应包装。
+	// 默认响应 schema 包装为 Response{code, message, data, trace_id}，data 为业务类型；
+	// 全部路由都用 c.JSON 原样输出时可关闭，单个路由可用 RouteBuilder.RawResponse()。
+	DisableResponseEnvelope bool
+
+	// DefaultErrors 所有 RouteBuilder 路由默认声明的错误响应，如 ErrServer、ErrUnauthorized。
+	// 与 RouteBuilder.Errors() 合并后按 HTTP 状态码分组输出。
+	DefaultErrors []*errors.Error
+
+	// SecuritySchemes 安全方案，key 为方案名，如 {"bearer": qi.BearerAuth("JWT")}。
+	SecuritySchemes map[string]*SecurityScheme
+	// Security 全局默认安全要求（方案名），多个方案任一满足即可。
+	// 可被 RouterGroup.Security() / RouteBuilder.Security() 覆盖。
+	Security []string
+
+	// Unions 接口类型的多态实现，接口字段、切片元素及请求/响应体按 oneOf/anyOf 生成 schema。
+	Unions []Union
+}
+
+// OpenAPIDocument 定义一个命名子文档，如对外公开的 API 和内部管理 API 分开发布。
+// Tags 和 PathPrefixes 同时设置时两者都需满足。
+type OpenAPIDocument struct {
+	Name         string   // 文档名，用于端点路径
+	Title        string   // 文档标题，空则沿用主文档
+	Description  string   // 文档描述，空则沿用主文档
+	Tags         []string // 包含任一标签的路由
+	PathPrefixes []string // 路径（OpenAPI 风格，如 /admin/{id}）以任一前缀开头的路由
+}
+
+// filter 返回子文档的操作过滤器。
+func (d OpenAPIDocument) filter() func(openapi.Operation) bool {
+	var filters []func(openapi.Operation) bool
+	if len(d.Tags) > 0 {
+		filters = append(filters, openapi.FilterByTags(d.Tags...))
+	}
+	if len(d.PathPrefixes) > 0 {
+		filters = append(filters, openapi.FilterByPathPrefix(d.PathPrefixes...))
+	}
+	return openapi.FilterAll(filters...)
 }
 
 // normalize 填充默认值。
@@ -98,11 +286,80 @@ func (c *OpenAPIConfig) normalize() {
 	if c.Path == "" {
 		c.Path = "/openapi.json"
 	}
+	if c.YAMLPath == "" {
+		c.YAMLPath = strings.TrimSuffix(c.Path, ".json") + ".yaml"
+	}
 	if c.SwaggerUI == "" {
 		c.SwaggerUI = "/docs"
 	}
 }
 
+// ===== spec 端点 =====
+
+// specFormat 是 spec 端点的输出格式。
+type specFormat string
+
+const (
+	specFormatJSON specFormat = "json"
+	specFormatYAML specFormat = "yaml"
+)
+
+// specCacheSize 过滤结果缓存上限，避免任意查询参数撑大内存。
+const specCacheSize = 64
+
+// specHandler 返回 spec 端点 handler。
+// 无查询参数时返回 prebuilt（为 nil 时按需构建）；带 ?tag=a&tag=b 或 ?prefix=/admin 时
+// 基于 CloneWithOptions 过滤后构建。结果按查询条件缓存，Run 之后路由不再变化。
+func specHandler(m *openapi.Manager, format specFormat, prebuilt []byte) gin.HandlerFunc {
+	var (
+		mu    sync.Mutex
+		cache = make(map[string][]byte)
+	)
+	contentType := "application/json"
+	if format == specFormatYAML {
+		contentType = "application/yaml"
+	}
+
+	return func(c *gin.Context) {
+		doc := OpenAPIDocument{Tags: c.QueryArray("tag"), PathPrefixes: c.QueryArray("prefix")}
+		filtered := len(doc.Tags) > 0 || len(doc.PathPrefixes) > 0
+		if !filtered && prebuilt != nil {
+			c.Data(http.StatusOK, contentType, prebuilt)
+			return
+		}
+
+		key := strings.Join(doc.Tags, "\x00") + "\x01" + strings.Join(doc.PathPrefixes, "\x00")
+		mu.Lock()
+		data, ok := cache[key]
+		mu.Unlock()
+
+		if !ok {
+			target := m
+			if filtered {
+				target = m.CloneWithOptions(openapi.WithOperationFilter(doc.filter()))
+			}
+			var err error
+			if format == specFormatYAML {
+				data, err = target.MarshalYAML()
+			} else {
+				data, err = target.MarshalJSON()
+			}
+			if err != nil {
+				log.Printf("qi: OpenAPI spec build failed: %v", err)
+				c.Abort()
+				(&Context{ctx: c}).Fail(ErrServer)
+				return
+			}
+			mu.Lock()
+			if len(cache) < specCacheSize {
+				cache[key] = data
+			}
+			mu.Unlock()
+		}
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
+
 // ===== WithOpenAPI Engine Option =====
 
 // WithOpenAPI 启用 OpenAPI 文档收集，传入 OpenAPIConfig 结构体配置。
@@ -137,6 +394,152 @@ func (e *Engine) OpenAPIYAML() ([]byte, error) {
 	return e.api.MarshalYAML()
 }
 
+// ExportOpenAPI 构建 OpenAPI 文档并写入 path，不需要启动 HTTP 服务，用于 CI 生成和比对 spec。
+// format 取值 "json" / "yaml"，为空时按扩展名推断（.yaml/.yml 为 YAML，其余为 JSON）；
+// path 为 "-" 时写到标准输出。需在所有路由注册完成后调用。
+func (e *Engine) ExportOpenAPI(path, format string) error {
+	if e.api == nil {
+		return fmt.Errorf("qi: OpenAPI is not enabled, use WithOpenAPI")
+	}
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			format = string(specFormatYAML)
+		default:
+			format = string(specFormatJSON)
+		}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch specFormat(strings.ToLower(format)) {
+	case specFormatJSON:
+		data, err = e.api.MarshalJSON()
+	case specFormatYAML:
+		data, err = e.api.MarshalYAML()
+	default:
+		return fmt.Errorf("qi: unsupported OpenAPI format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// GoClient 根据已注册路由生成类型化的 Go 客户端源码，pkg 为包名（为空时为 "client"）。
+// 业务码非 0 的响应在客户端中返回 *errors.Error。需在所有路由注册完成后调用。
+func (e *Engine) GoClient(pkg string) ([]byte, error) {
+	doc, cfg, err := e.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Package = pkg
+	return clientgen.GenerateGo(doc, cfg)
+}
+
+// TypeScriptClient 根据已注册路由生成基于 fetch 的 TypeScript 客户端源码。
+func (e *Engine) TypeScriptClient() ([]byte, error) {
+	doc, cfg, err := e.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return clientgen.GenerateTypeScript(doc, cfg)
+}
+
+// ExportClient 生成客户端并写入 path，按扩展名选择语言：.go 为 Go（包名取所在目录名），.ts 为 TypeScript。
+func (e *Engine) ExportClient(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		pkg := filepath.Base(filepath.Dir(path))
+		if abs, absErr := filepath.Abs(path); absErr == nil {
+			pkg = filepath.Base(filepath.Dir(abs))
+		}
+		data, err = e.GoClient(clientPackageName(pkg))
+	case ".ts":
+		data, err = e.TypeScriptClient()
+	default:
+		return fmt.Errorf("qi: unsupported client file %q, use .go or .ts", path)
+	}
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// clientConfig 构建文档并收集客户端生成配置，方法名取自路由的 handler 函数名。
+func (e *Engine) clientConfig() (*openapi.Document, clientgen.Config, error) {
+	if e.api == nil {
+		return nil, clientgen.Config{}, fmt.Errorf("qi: OpenAPI is not enabled, use WithOpenAPI")
+	}
+	doc, err := e.api.Build()
+	if err != nil {
+		return nil, clientgen.Config{}, err
+	}
+
+	cfg := clientgen.Config{Names: make(map[string]string)}
+	for _, r := range e.Routes() {
+		cfg.Names[r.Method+" "+ginPathToOpenAPI(r.FullPath)] = r.HandlerName
+	}
+	if env := e.envelope(); env != nil {
+		cfg.Envelope = &clientgen.Envelope{
+			Component:    env.Name,
+			CodeField:    env.CodeField,
+			MessageField: env.MessageField,
+			DataField:    env.DataField,
+			TraceIDField: env.TraceIDField,
+			SuccessCode:  env.SuccessCode,
+		}
+	}
+	return doc, cfg, nil
+}
+
+// envelope 返回 OpenAPI 文档和客户端使用的响应包装结构：来自 ResponseRenderer，
+// DisableResponseEnvelope 时为 nil。
+func (e *Engine) envelope() *Envelope {
+	if e.cfg.openAPIConfig != nil && e.cfg.openAPIConfig.DisableResponseEnvelope {
+		return nil
+	}
+	return e.renderer().Envelope()
+}
+
+// clientPackageName 将目录名转换为合法的 Go 包名。
+func clientPackageName(dir string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(dir) {
+		if r == '_' || (r >= 'a' && r <= 'z') || (b.Len() > 0 && r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "client"
+	}
+	return b.String()
+}
+
 // API 创建一个 RouteBuilder，用于链式注册路由并收集 OpenAPI 信息。
 func (e *Engine) API() *RouteBuilder {
 	return &RouteBuilder{
@@ -147,13 +550,96 @@ func (e *Engine) API() *RouteBuilder {
 
 // ===== RouterGroup 方法 =====
 
+// groupAPIDefaults 分组级 OpenAPI 默认值。
+// 子分组创建时复制父分组的值后继续追加，RouteBuilder 创建时合并到每个路由。
+type groupAPIDefaults struct {
+	tags              []string
+	security          []openapi.SecurityRequirement // nil=继承全局默认，空切片=公开
+	securityInherited bool                          // security 继承自上级分组，首次调用 Security 时替换
+	headers           []any
+	cookies           []any
+	errs              []*errors.Error
+	descriptionPrefix string
+	deprecated        bool
+}
+
+// clone 复制一份，避免子分组修改影响父分组。
+func (d groupAPIDefaults) clone() groupAPIDefaults {
+	return groupAPIDefaults{
+		tags:              append([]string(nil), d.tags...),
+		security:          cloneSecurity(d.security),
+		headers:           append([]any(nil), d.headers...),
+		cookies:           append([]any(nil), d.cookies...),
+		errs:              append([]*errors.Error(nil), d.errs...),
+		descriptionPrefix: d.descriptionPrefix,
+		deprecated:        d.deprecated,
+		securityInherited: d.securityInherited,
+	}
+}
+
 // API 创建一个 RouteBuilder，用于链式注册路由并收集 OpenAPI 信息。
+// 分组的 OpenAPI 默认值（标签、安全要求、公共参数、错误响应等）合并到该路由。
 func (r *RouterGroup) API() *RouteBuilder {
 	return &RouteBuilder{
 		engine:      r.engine,
 		prefix:      r.prefix,
 		middlewares: cloneHandlers(r.middlewares),
+		group:       r.api.clone(),
+	}
+}
+
+// Tags 追加分组内路由的默认标签，与路由 Tags() 合并去重，子分组继承。
+func (r *RouterGroup) Tags(tags ...string) *RouterGroup {
+	r.api.tags = append(r.api.tags, tags...)
+	return r
+}
+
+// Security 设置分组内路由的安全要求，覆盖上级分组和全局默认值，子分组继承。
+// 同一分组多次调用表示多个可选方案（任一满足即可）；子分组首次调用时替换继承的要求，而不是追加。
+func (r *RouterGroup) Security(name string, scopes ...string) *RouterGroup {
+	if r.api.securityInherited {
+		r.api.security = nil
+		r.api.securityInherited = false
 	}
+	r.api.security = append(r.api.security, openapi.SecurityRequirement{name: append([]string{}, scopes...)})
+	return r
+}
+
+// NoSecurity 标记分组内路由为公开接口，不继承上级分组和全局默认安全要求。
+func (r *RouterGroup) NoSecurity() *RouterGroup {
+	r.api.security = []openapi.SecurityRequirement{}
+	r.api.securityInherited = false
+	return r
+}
+
+// Headers 追加分组内路由的公共请求头参数类型，同名参数以路由 Headers() 为准。
+func (r *RouterGroup) Headers(v any) *RouterGroup {
+	r.api.headers = append(r.api.headers, v)
+	return r
+}
+
+// Cookies 追加分组内路由的公共 Cookie 参数类型，同名参数以路由 Cookies() 为准。
+func (r *RouterGroup) Cookies(v any) *RouterGroup {
+	r.api.cookies = append(r.api.cookies, v)
+	return r
+}
+
+// Errors 追加分组内路由的公共错误响应，与路由 Errors() 合并。
+func (r *RouterGroup) Errors(errs ...*errors.Error) *RouterGroup {
+	r.api.errs = append(r.api.errs, errs...)
+	return r
+}
+
+// DescriptionPrefix 设置分组内路由描述的前缀，子分组的前缀拼接在父分组之后。
+func (r *RouterGroup) DescriptionPrefix(prefix string) *RouterGroup {
+	r.api.descriptionPrefix = joinDescription(r.api.descriptionPrefix, prefix)
+	return r
+}
+
+// Deprecated 标记分组内路由全部为已弃用。
+func (r *RouterGroup) Deprecated() *RouterGroup {
+	r.api.deprecated = true
+	return r
 }
 
 // ===== RouteBuilder =====
@@ -175,15 +661,27 @@ type RouteBuilder struct {
 	tags        []string
 	operationID string
 	deprecated  bool
+	rawResponse bool
+
+	// 安全要求：路由 > 分组 > 全局默认
+	security   []openapi.SecurityRequirement
+	noSecurity bool
+
+	// 分组继承的 OpenAPI 默认值
+	group groupAPIDefaults
 
 	// 请求响应
-	request    any // Request() 自动分发
-	query      any // 显式 Query
-	body       any // 显式 Body
-	pathParams any // 显式 PathParams
-	headers    any // 显式 Headers
-	cookies    any // 显式 Cookies
-	response   any // Response 类型
+	request         any      // Request() 自动分发
+	query           any      // 显式 Query
+	body            any      // 显式 Body
+	bodyContentType string   // 显式 Body Content-Type
+	pathParams      any      // 显式 PathParams
+	headers         any      // 显式 Headers
+	cookies         any      // 显式 Cookies
+	response        any      // Response 类型
+	produces        []string // 显式响应媒体类型
+	streamType      string   // 流式响应媒体类型（SSE / Stream）
+	errs            []*errors.Error
 
 	// Bind 推导的类型
 	boundRequest  reflect.Type // Bind 推导的请求类型
@@ -280,7 +778,7 @@ func (b *RouteBuilder) Description(desc string) *RouteBuilder {
 	return b
 }
 
-// Tags 设置操作标签。
+// Tags 设置操作标签，与分组默认标签合并去重。
 func (b *RouteBuilder) Tags(tags ...string) *RouteBuilder {
 	b.tags = tags
 	return b
@@ -298,6 +796,45 @@ func (b *RouteBuilder) Deprecated() *RouteBuilder {
 	return b
 }
 
+// Security 设置路由的安全要求，覆盖分组和全局默认值。
+// 多次调用表示多个可选方案（任一满足即可）。
+func (b *RouteBuilder) Security(name string, scopes ...string) *RouteBuilder {
+	b.security = append(b.security, openapi.SecurityRequirement{name: append([]string{}, scopes...)})
+	return b
+}
+
+// NoSecurity 标记路由为公开接口，不继承分组和全局默认安全要求。
+func (b *RouteBuilder) NoSecurity() *RouteBuilder {
+	b.noSecurity = true
+	return b
+}
+
+// RawResponse 声明该路由通过 c.JSON 等方式原样输出，响应 schema 不使用统一包装。
+func (b *RouteBuilder) RawResponse() *RouteBuilder {
+	b.rawResponse = true
+	return b
+}
+
+// Produces 设置统一响应的媒体类型，覆盖 WithEncoders 注册的默认列表（JSON 与各编码器）。
+// 如仅返回 proto.Message 的路由：Produces("application/json", "application/x-protobuf")。
+func (b *RouteBuilder) Produces(mediaTypes ...string) *RouteBuilder {
+	b.produces = append([]string(nil), mediaTypes...)
+	return b
+}
+
+// SSE 声明该路由通过 c.SSE 推送事件，响应为 text/event-stream，v 为事件数据类型（可为 nil）。
+func (b *RouteBuilder) SSE(v any) *RouteBuilder {
+	return b.Stream(MIMEEventStream, v)
+}
+
+// Stream 声明该路由通过 c.Stream 流式输出 contentType（如 "application/x-ndjson"），v 为单条数据类型（可为 nil）。
+func (b *RouteBuilder) Stream(contentType string, v any) *RouteBuilder {
+	b.streamType = contentType
+	b.response = v
+	b.rawResponse = true
+	return b
+}
+
 // ----- 请求响应 -----
 
 // Request 设置请求类型。根据 HTTP 方法自动分发：
@@ -321,8 +858,13 @@ func (b *RouteBuilder) Query(v any) *RouteBuilder {
 }
 
 // Body 显式设置请求体类型，覆盖 Request 自动分发。
-func (b *RouteBuilder) Body(v any) *RouteBuilder {
+// contentType 可选，如 "multipart/form-data"、"application/x-www-form-urlencoded"，
+// 表单类型按 form tag 生成字段；未指定时默认 application/json，含上传文件字段时自动识别为 multipart/form-data。
+func (b *RouteBuilder) Body(v any, contentType ...string) *RouteBuilder {
 	b.body = v
+	if len(contentType) > 0 {
+		b.bodyContentType = contentType[0]
+	}
 	return b
 }
 
@@ -344,6 +886,13 @@ func (b *RouteBuilder) Cookies(v any) *RouteBuilder {
 	return b
 }
 
+// Errors 声明路由可能返回的业务错误，按 Status() 分组生成 4xx/5xx 响应，
+// 同一状态码下的业务码和消息作为示例列出。可多次调用，按业务码去重。
+func (b *RouteBuilder) Errors(errs ...*errors.Error) *RouteBuilder {
+	b.errs = append(b.errs, errs...)
+	return b
+}
+
 // ----- 终结方法 -----
 
 // Done 注册路由并收集 OpenAPI 信息。始终注册 gin 路由，
@@ -362,13 +911,18 @@ func (b *RouteBuilder) Done() {
 		}
 	}
 
+	// 合并分组默认值
+	tags := mergeTags(b.group.tags, b.tags)
+	description := joinDescription(b.group.descriptionPrefix, b.description)
+	deprecated := b.deprecated || b.group.deprecated
+
 	// 2. 写入路由元信息注册表（无论 OpenAPI 是否启用，始终写入）
 	b.engine.SetRouteMeta(b.method, fullPath, RouteMeta{
 		Summary:     b.summary,
-		Description: b.description,
-		Tags:        b.tags,
+		Description: description,
+		Tags:        tags,
 		OperationID: b.operationID,
-		Deprecated:  b.deprecated,
+		Deprecated:  deprecated,
 	})
 
 	// 3. 如果 OpenAPI 未启用，直接返回
@@ -382,9 +936,9 @@ func (b *RouteBuilder) Done() {
 		Path:        ginPathToOpenAPI(fullPath),
 		OperationID: b.operationID,
 		Summary:     b.summary,
-		Description: b.description,
-		Tags:        b.tags,
-		Deprecated:  b.deprecated,
+		Description: description,
+		Tags:        tags,
+		Deprecated:  deprecated,
 	}
 
 	// 3a. 构建 Request
@@ -431,8 +985,18 @@ func (b *RouteBuilder) Done() {
 	if b.request == nil && b.body == nil && b.query == nil && b.boundRequest != nil {
 		v := reflect.New(b.boundRequest).Elem().Interface()
 		if isBodyMethod(b.method) {
-			req.Body = v
-			req.BodyRequired = true
+			// 同一结构体按 tag 拆分：表单请求体时 form 字段属于请求体，否则生成查询参数
+			formBody := isFormContentType(b.bodyContentType) || typeHasFileField(b.boundRequest)
+			if typeHasBodyField(b.boundRequest, formBody) {
+				req.Body = v
+				req.BodyRequired = true
+			}
+			if !formBody && typeHasTag(b.boundRequest, "form") {
+				req.QueryParams = v
+			}
+			if typeHasTag(b.boundRequest, "uri") {
+				req.PathParams = v
+			}
 		} else {
 			// 有 uri tag 时：用真实结构体覆盖自动提取的 PathParams（保留 desc/example 等信息）
 			if typeHasTag(b.boundRequest, "uri") {
@@ -440,9 +1004,24 @@ func (b *RouteBuilder) Done() {
 			}
 			req.QueryParams = v
 		}
+		// 带 header / cookie tag 的字段同时生成请求头和 Cookie 参数，显式 Headers()/Cookies() 优先
+		if b.headers == nil && typeHasTag(b.boundRequest, "header") {
+			req.Headers = v
+		}
+		if b.cookies == nil && typeHasTag(b.boundRequest, "cookie") {
+			req.Cookies = v
+		}
 		hasRequest = true
 	}
 
+	// 请求体 Content-Type: 显式 > 含上传文件字段时自动识别为 multipart/form-data
+	if req.Body != nil {
+		req.BodyContentType = b.bodyContentType
+		if req.BodyContentType == "" && typeHasFileField(reflect.TypeOf(req.Body)) {
+			req.BodyContentType = "multipart/form-data"
+		}
+	}
+
 	// Headers / Cookies
 	if b.headers != nil {
 		req.Headers = b.headers
@@ -452,18 +1031,42 @@ func (b *RouteBuilder) Done() {
 		req.Cookies = b.cookies
 		hasRequest = true
 	}
+	if len(b.group.headers) > 0 {
+		req.CommonHeaders = append([]any(nil), b.group.headers...)
+		hasRequest = true
+	}
+	if len(b.group.cookies) > 0 {
+		req.CommonCookies = append([]any(nil), b.group.cookies...)
+		hasRequest = true
+	}
 
 	if hasRequest {
 		op.Request = req
 	}
 
-	// 3b. Response: 显式 .Response() > boundResponse（Bind 推导）
-	if b.response != nil {
+	// 3b. Response: SSE() / Stream() > 显式 .Response() > boundResponse（Bind 推导）
+	// BindE/BindRE 无响应类型，但仍返回 c.OK(nil)，记录一个仅含统一包装的 200 响应
+	if b.streamType != "" {
+		body := b.response
+		if body == nil {
+			body = "" // 未声明数据类型时按字符串描述
+		}
+		op.Responses = []openapi.Response{
+			{
+				Status:      200,
+				Description: "成功",
+				Body:        body,
+				ContentType: b.streamType,
+				Raw:         true,
+			},
+		}
+	} else if b.response != nil {
 		op.Responses = []openapi.Response{
 			{
 				Status:      200,
 				Description: "成功",
 				Body:        b.response,
+				Raw:         b.rawResponse,
 			},
 		}
 	} else if b.boundResponse != nil {
@@ -472,12 +1075,180 @@ func (b *RouteBuilder) Done() {
 				Status:      200,
 				Description: "成功",
 				Body:        reflect.New(b.boundResponse).Elem().Interface(),
+				Raw:         b.rawResponse,
+			},
+		}
+	} else if b.boundFuncName != "" {
+		op.Responses = []openapi.Response{
+			{
+				Status:      200,
+				Description: "成功",
+				Raw:         b.rawResponse,
 			},
 		}
 	}
 
+	// 3c. 错误响应：无成功响应时补充默认 200，避免文档只剩错误响应
+	if errResponses := b.errorResponses(); len(errResponses) > 0 {
+		if len(op.Responses) == 0 {
+			op.Responses = append(op.Responses, openapi.Response{Status: 200, Raw: true})
+		}
+		op.Responses = append(op.Responses, errResponses...)
+	}
+
+	// 3d. 统一响应按 Accept 协商时列出所有媒体类型，原样输出的响应不参与协商
+	if produces := b.mediaTypes(); len(produces) > 0 {
+		for i := range op.Responses {
+			if !op.Responses[i].Raw && op.Responses[i].ContentType == "" {
+				op.Responses[i].MediaTypes = produces
+			}
+		}
+	}
+
+	// 3e. 安全要求
+	op.Security = b.resolveSecurity()
+
 	// 4. 注册 OpenAPI Operation（失败则 panic，启动时快速失败）
 	if err := b.engine.api.AddOperation(op); err != nil {
 		panic("qi: OpenAPI AddOperation failed: " + err.Error())
 	}
 }
+
+// mediaTypes 返回统一响应的媒体类型：显式 Produces() > WithEncoders 注册的编码器，
+// 仅支持 JSON 时返回 nil。
+func (b *RouteBuilder) mediaTypes() []string {
+	if len(b.produces) > 0 {
+		return b.produces
+	}
+	if len(b.engine.cfg.encoders) == 0 {
+		return nil
+	}
+	return mediaTypes(b.engine.cfg.encoders)
+}
+
+// errorResponses 合并全局 DefaultErrors、Bind 绑定失败错误、分组和路由 Errors()，
+// 按业务码去重后以 HTTP 状态码分组，每个业务码生成一个示例。
+func (b *RouteBuilder) errorResponses() []openapi.Response {
+	var errs []*errors.Error
+	if cfg := b.engine.cfg.openAPIConfig; cfg != nil {
+		errs = append(errs, cfg.DefaultErrors...)
+	}
+	// Bind/BindE 绑定失败时返回 ErrBadRequest
+	if b.boundRequest != nil {
+		errs = append(errs, ErrBadRequest)
+	}
+	errs = append(errs, b.group.errs...)
+	errs = append(errs, b.errs...)
+
+	seen := make(map[int]struct{}, len(errs))
+	byStatus := make(map[int][]*errors.Error)
+	for _, e := range errs {
+		if e == nil {
+			continue
+		}
+		if _, ok := seen[e.Code]; ok {
+			continue
+		}
+		seen[e.Code] = struct{}{}
+		byStatus[e.Status()] = append(byStatus[e.Status()], e)
+	}
+
+	statuses := make([]int, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	renderer := b.engine.renderer()
+	out := make([]openapi.Response, 0, len(statuses))
+	for _, status := range statuses {
+		desc := http.StatusText(status)
+		examples := make([]openapi.ResponseExample, 0, len(byStatus[status]))
+		for _, e := range byStatus[status] {
+			code := strconv.Itoa(e.Code)
+			desc += "\n- " + code + ": " + e.Message
+			examples = append(examples, openapi.ResponseExample{
+				Name:    code,
+				Summary: e.Message,
+				Value:   renderer.Render(nil, Result{Status: status, Code: e.Code, Message: e.Message, Err: e}),
+			})
+		}
+		resp := openapi.Response{
+			Status:      status,
+			Description: desc,
+			Examples:    examples,
+		}
+		// 错误响应使用独立结构（如 RFC 7807）时原样输出，不参与统一响应包装和内容协商
+		if env := renderer.Envelope(); env != nil && env.ErrorType != nil {
+			resp.Body = env.ErrorType
+			resp.ContentType = env.ErrorMediaType
+			resp.Raw = true
+		}
+		out = append(out, resp)
+	}
+	return out
+}
+
+// resolveSecurity 按 路由 > 分组 > 全局默认 的优先级确定安全要求。
+func (b *RouteBuilder) resolveSecurity() []openapi.SecurityRequirement {
+	switch {
+	case b.noSecurity:
+		return nil
+	case len(b.security) > 0:
+		return cloneSecurity(b.security)
+	case b.group.security != nil:
+		return cloneSecurity(b.group.security)
+	}
+	var out []openapi.SecurityRequirement
+	if cfg := b.engine.cfg.openAPIConfig; cfg != nil {
+		for _, name := range cfg.Security {
+			out = append(out, openapi.SecurityRequirement{name: []string{}})
+		}
+	}
+	return out
+}
+
+// cloneSecurity 深拷贝安全要求，保留 nil 与空切片的区别（空切片表示公开）。
+func cloneSecurity(reqs []openapi.SecurityRequirement) []openapi.SecurityRequirement {
+	if reqs == nil {
+		return nil
+	}
+	out := make([]openapi.SecurityRequirement, 0, len(reqs))
+	for _, req := range reqs {
+		cp := make(openapi.SecurityRequirement, len(req))
+		for name, scopes := range req {
+			cp[name] = append([]string{}, scopes...)
+		}
+		out = append(out, cp)
+	}
+	return out
+}
+
+// mergeTags 合并分组标签和路由标签，保持顺序并去重。
+func mergeTags(groups, tags []string) []string {
+	if len(groups) == 0 {
+		return tags
+	}
+	seen := make(map[string]struct{}, len(groups)+len(tags))
+	out := make([]string, 0, len(groups)+len(tags))
+	for _, tag := range append(append([]string(nil), groups...), tags...) {
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out
+}
+
+// joinDescription 拼接描述前缀和描述，任一为空时返回另一个。
+func joinDescription(prefix, desc string) string {
+	switch {
+	case prefix == "":
+		return desc
+	case desc == "":
+		return prefix
+	default:
+		return prefix + "\n\n" + desc
+	}
+}
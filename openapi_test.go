@@ -0,0 +1,548 @@
+package qi
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tokmz/qi/internal/openapi"
+	"github.com/tokmz/qi/pkg/errors"
+)
+
+// buildTestDoc 构建 OpenAPI 文档，失败时终止测试。
+func buildTestDoc(t *testing.T, e *Engine) *openapi.Document {
+	t.Helper()
+	doc, err := e.OpenAPI().Build()
+	if err != nil {
+		t.Fatalf("build openapi: %v", err)
+	}
+	return doc
+}
+
+func TestRouteBuilder_ResponseEnvelope(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{Title: "test"}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+	e.API().DELETE("/users/:id", BindE(func(c *Context, req *getUserReq) error {
+		return nil
+	})).Done()
+	e.API().GET("/raw", func(c *Context) {
+		c.JSON(200, userResp{})
+	}).Response(userResp{}).RawResponse().Done()
+
+	doc := buildTestDoc(t, e)
+
+	if doc.Components.Schemas["Response"] == nil {
+		t.Fatal("missing Response envelope component")
+	}
+	got := doc.Paths["/users/{id}"].Get.Responses["200"].Content["application/json"].Schema
+	if got.Ref != "#/components/schemas/Response_github.com.tokmz.qi.userResp" {
+		t.Errorf("Bind response should be wrapped, got %#v", got)
+	}
+	got = doc.Paths["/users/{id}"].Delete.Responses["200"].Content["application/json"].Schema
+	if got.Ref != "#/components/schemas/Response" {
+		t.Errorf("BindE response should ref envelope, got %#v", got)
+	}
+	got = doc.Paths["/raw"].Get.Responses["200"].Content["application/json"].Schema
+	if got.Ref != "#/components/schemas/github.com.tokmz.qi.userResp.Response" {
+		t.Errorf("raw response should not be wrapped, got %#v", got)
+	}
+}
+
+func TestRouteBuilder_DisableResponseEnvelope(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{DisableResponseEnvelope: true}))
+	e.API().GET("/users", BindR(func(c *Context) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	doc := buildTestDoc(t, e)
+
+	if doc.Components.Schemas["Response"] != nil {
+		t.Error("envelope component should not exist when disabled")
+	}
+	got := doc.Paths["/users"].Get.Responses["200"].Content["application/json"].Schema
+	if got.Ref != "#/components/schemas/github.com.tokmz.qi.userResp.Response" {
+		t.Errorf("response should not be wrapped, got %#v", got)
+	}
+}
+
+func TestRouteBuilder_Errors(t *testing.T) {
+	errUserExists := errors.NewWithStatus(2002, http.StatusConflict, "user already exists")
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{
+		DefaultErrors: []*errors.Error{ErrServer},
+	}))
+	e.API().POST("/users", Bind(func(c *Context, req *createUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Errors(errUserExists, ErrInvalidParams, ErrServer).Done()
+	e.API().GET("/ping", func(c *Context) { c.OK("pong") }).Done()
+
+	doc := buildTestDoc(t, e)
+	responses := doc.Paths["/users"].Post.Responses
+
+	bad := responses["400"]
+	if bad == nil {
+		t.Fatal("missing 400 response")
+	}
+	examples := bad.Content["application/json"].Examples
+	if examples["1001"] == nil || examples["1100"] == nil {
+		t.Errorf("400 should list ErrBadRequest and ErrInvalidParams, got %v", examples)
+	}
+	if bad.Content["application/json"].Schema.Ref != "#/components/schemas/Response" {
+		t.Errorf("error response should ref envelope, got %#v", bad.Content["application/json"].Schema)
+	}
+	if responses["409"] == nil || responses["409"].Content["application/json"].Examples["2002"] == nil {
+		t.Error("missing 409 response for business error")
+	}
+	if len(responses["500"].Content["application/json"].Examples) != 1 {
+		t.Error("duplicated errors should be merged by code")
+	}
+
+	ping := doc.Paths["/ping"].Get.Responses
+	if ping["200"] == nil || ping["500"] == nil {
+		t.Errorf("plain handler should keep 200 and get default errors, got %v", ping)
+	}
+}
+
+func TestRouteBuilder_Security(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{
+		SecuritySchemes: map[string]*SecurityScheme{
+			"bearer": BearerAuth("JWT"),
+			"apiKey": APIKeyAuth("header", "X-API-Key"),
+		},
+		Security: []string{"bearer"},
+	}))
+	handler := func(c *Context) { c.OK(nil) }
+
+	e.API().GET("/me", handler).Done()
+	e.API().GET("/login", handler).NoSecurity().Done()
+
+	admin := e.Group("/admin").Security("apiKey")
+	admin.API().GET("/stats", handler).Done()
+	admin.Group("/ops").API().GET("/jobs", handler).Security("bearer", "ops").Done()
+	e.Group("/public").NoSecurity().API().GET("/news", handler).Done()
+
+	doc := buildTestDoc(t, e)
+
+	if s := doc.Components.SecuritySchemes["bearer"]; s == nil || s.Scheme != "bearer" {
+		t.Fatalf("missing bearer scheme, got %#v", doc.Components.SecuritySchemes)
+	}
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/me", "bearer"},
+		{"/admin/stats", "apiKey"},
+		{"/admin/ops/jobs", "bearer"},
+	}
+	for _, tc := range cases {
+		sec := doc.Paths[tc.path].Get.Security
+		if len(sec) != 1 {
+			t.Errorf("%s security = %v, want [%s]", tc.path, sec, tc.want)
+			continue
+		}
+		if _, ok := sec[0][tc.want]; !ok {
+			t.Errorf("%s security = %v, want %s", tc.path, sec, tc.want)
+		}
+	}
+	if scopes := doc.Paths["/admin/ops/jobs"].Get.Security[0]["bearer"]; len(scopes) != 1 || scopes[0] != "ops" {
+		t.Errorf("scopes = %v, want [ops]", scopes)
+	}
+	for _, path := range []string{"/login", "/public/news"} {
+		if sec := doc.Paths[path].Get.Security; len(sec) != 0 {
+			t.Errorf("%s should be public, got %v", path, sec)
+		}
+	}
+}
+
+func TestRouterGroup_NestedSecurityReplacesParent(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{
+		SecuritySchemes: map[string]*SecurityScheme{
+			"bearer": BearerAuth("JWT"),
+			"apiKey": APIKeyAuth("header", "X-API-Key"),
+			"mtls":   APIKeyAuth("header", "X-Client-Cert"),
+		},
+	}))
+	handler := func(c *Context) { c.OK(nil) }
+
+	admin := e.Group("/admin").Security("bearer")
+	admin.Group("/audit").API().GET("/logs", handler).Done()
+	admin.Group("/internal").Security("apiKey").API().GET("/jobs", handler).Done()
+	admin.Group("/ops").Security("apiKey").Security("mtls").API().GET("/deploy", handler).Done()
+	admin.API().GET("/stats", handler).Done()
+
+	doc := buildTestDoc(t, e)
+
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{"/admin/stats", []string{"bearer"}},
+		{"/admin/audit/logs", []string{"bearer"}}, // 未调用 Security 时继承上级
+		{"/admin/internal/jobs", []string{"apiKey"}},
+		{"/admin/ops/deploy", []string{"apiKey", "mtls"}}, // 同一分组多次调用为可选方案
+	}
+	for _, tc := range cases {
+		sec := doc.Paths[tc.path].Get.Security
+		var got []string
+		for _, req := range sec {
+			for name := range req {
+				got = append(got, name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s security = %v, want %v", tc.path, sec, tc.want)
+		}
+	}
+}
+
+func TestRouteBuilder_UndefinedSecurityPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for undefined security scheme")
+		}
+	}()
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}))
+	e.API().GET("/me", func(c *Context) {}).Security("missing").Done()
+}
+
+type tenantHeader struct {
+	TenantID string `header:"X-Tenant-Id" binding:"required" desc:"租户ID"`
+}
+
+type traceHeader struct {
+	TenantID string `header:"X-Tenant-Id" desc:"覆盖后的租户ID"`
+	TraceID  string `header:"X-Trace-Id"`
+}
+
+func TestRouterGroup_OpenAPIDefaults(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}))
+	handler := func(c *Context) { c.OK(nil) }
+
+	admin := e.Group("/admin").
+		Tags("admin").
+		Headers(tenantHeader{}).
+		Errors(ErrForbidden).
+		DescriptionPrefix("管理后台接口")
+	admin.API().GET("/stats", handler).Tags("stats", "admin").Description("统计").Done()
+
+	legacy := admin.Group("/v0").Tags("legacy").Deprecated()
+	legacy.API().GET("/users", handler).Headers(traceHeader{}).Done()
+
+	// 父分组后续修改不影响已创建的子分组
+	admin.Tags("late")
+
+	doc := buildTestDoc(t, e)
+
+	stats := doc.Paths["/admin/stats"].Get
+	if len(stats.Tags) != 2 || stats.Tags[0] != "admin" || stats.Tags[1] != "stats" {
+		t.Errorf("tags = %v, want [admin stats]", stats.Tags)
+	}
+	if stats.Description != "管理后台接口\n\n统计" {
+		t.Errorf("description = %q", stats.Description)
+	}
+	if len(stats.Parameters) != 1 || stats.Parameters[0].Name != "X-Tenant-Id" || !stats.Parameters[0].Required {
+		t.Errorf("parameters = %v, want required X-Tenant-Id", stats.Parameters)
+	}
+	if stats.Responses["403"] == nil {
+		t.Error("group errors should be documented")
+	}
+
+	users := doc.Paths["/admin/v0/users"].Get
+	if !users.Deprecated {
+		t.Error("nested group route should be deprecated")
+	}
+	if len(users.Tags) != 2 || users.Tags[1] != "legacy" {
+		t.Errorf("tags = %v, want [admin legacy]", users.Tags)
+	}
+	if len(users.Parameters) != 2 {
+		t.Fatalf("parameters = %v, want 2", users.Parameters)
+	}
+	for _, p := range users.Parameters {
+		if p.Name == "X-Tenant-Id" && p.Description != "覆盖后的租户ID" {
+			t.Errorf("route headers should override group headers, got %q", p.Description)
+		}
+	}
+
+	meta := e.RouteMeta("GET", "/admin/v0/users")
+	if meta == nil || !meta.Deprecated || len(meta.Tags) != 2 {
+		t.Errorf("route meta should include group defaults, got %#v", meta)
+	}
+}
+
+type uploadAvatarReq struct {
+	Name   string                `form:"name"`
+	Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+}
+
+type loginForm struct {
+	Username string `form:"username"`
+	Password string `form:"password"`
+}
+
+func TestRouteBuilder_MultipartBody(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}))
+	e.API().POST("/avatar", BindE(func(c *Context, req *uploadAvatarReq) error {
+		return nil
+	})).Done()
+	e.API().POST("/login", func(c *Context) {}).
+		Body(loginForm{}, "application/x-www-form-urlencoded").Done()
+
+	doc := buildTestDoc(t, e)
+
+	if doc.Paths["/avatar"].Post.RequestBody.Content["multipart/form-data"] == nil {
+		t.Errorf("file upload should be detected as multipart, got %v", doc.Paths["/avatar"].Post.RequestBody.Content)
+	}
+	login := doc.Paths["/login"].Post.RequestBody.Content["application/x-www-form-urlencoded"]
+	if login == nil {
+		t.Fatalf("explicit content type should be used, got %v", doc.Paths["/login"].Post.RequestBody.Content)
+	}
+	form := doc.Components.Schemas["github.com.tokmz.qi.loginForm.Form"]
+	if form == nil || form.Properties["username"] == nil {
+		t.Errorf("form body should use form tags, got %#v", form)
+	}
+}
+
+func TestRouteBuilder_BindHeaderAndCookieParams(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}))
+	handler := Bind(func(c *Context, req *tenantReq) (*userResp, error) {
+		return &userResp{}, nil
+	})
+	e.API().GET("/tenants", handler).Done()
+	e.API().POST("/tenants", handler).Done()
+
+	doc := buildTestDoc(t, e)
+
+	params := make(map[string]string)
+	for _, p := range doc.Paths["/tenants"].Get.Parameters {
+		params[p.In+":"+p.Name] = p.In
+		if p.Name == "X-Tenant-Id" && !p.Required {
+			t.Error("X-Tenant-Id should be required")
+		}
+	}
+	for _, key := range []string{"header:X-Tenant-Id", "cookie:session", "query:page"} {
+		if _, ok := params[key]; !ok {
+			t.Errorf("missing parameter %s, got %v", key, params)
+		}
+	}
+	for _, key := range []string{"query:Tenant", "query:Session", "header:Session", "cookie:Tenant"} {
+		if _, ok := params[key]; ok {
+			t.Errorf("unexpected parameter %s, got %v", key, params)
+		}
+	}
+
+	post := doc.Paths["/tenants"].Post
+	if len(post.Parameters) != 3 {
+		t.Errorf("POST parameters = %d, want header, cookie and query page", len(post.Parameters))
+	}
+	body := doc.Components.Schemas["github.com.tokmz.qi.tenantReq.Body"]
+	if body == nil || body.Properties["Tenant"] != nil || body.Properties["Session"] != nil {
+		t.Errorf("body schema should exclude header/cookie fields, got %#v", body)
+	}
+}
+
+func TestRouteBuilder_BindSplitsQueryAndBody(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}))
+	e.API().PUT("/orders/:id", Bind(func(c *Context, req *updateOrderReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	doc := buildTestDoc(t, e)
+
+	op := doc.Paths["/orders/{id}"].Put
+	params := make(map[string]bool)
+	for _, p := range op.Parameters {
+		params[p.In+":"+p.Name] = true
+	}
+	for _, key := range []string{"path:id", "query:dry_run", "query:limit"} {
+		if !params[key] {
+			t.Errorf("missing parameter %s, got %v", key, params)
+		}
+	}
+	if len(params) != 3 {
+		t.Errorf("parameters = %v, want path id and query dry_run/limit", params)
+	}
+	body := doc.Components.Schemas["github.com.tokmz.qi.updateOrderReq.Body"]
+	if op.RequestBody == nil || body == nil || len(body.Properties) != 1 || body.Properties["name"] == nil {
+		t.Errorf("body schema should only contain name, got %#v", body)
+	}
+}
+
+func TestEngine_OpenAPIEndpoints(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{
+		Title: "main",
+		Documents: []OpenAPIDocument{
+			{Name: "public", Title: "public", Tags: []string{"public"}},
+			{Name: "admin", PathPrefixes: []string{"/admin"}},
+		},
+	}))
+	handler := func(c *Context) { c.OK(nil) }
+	e.API().GET("/news", handler).Tags("public").Done()
+	e.API().GET("/admin/users", handler).Tags("admin").Done()
+	e.buildOpenAPISpec()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	paths := func(w *httptest.ResponseRecorder) map[string]any {
+		m, err := parseResponse(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("invalid json: %v, body = %s", err, w.Body.String())
+		}
+		p, _ := m["paths"].(map[string]any)
+		return p
+	}
+
+	if got := paths(get("/openapi.json")); len(got) != 2 {
+		t.Errorf("full spec paths = %v, want 2", got)
+	}
+	w := get("/openapi.yaml")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("yaml endpoint status = %d, content-type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "openapi: 3.0.3") {
+		t.Errorf("yaml body = %s", w.Body.String())
+	}
+	if got := paths(get("/openapi.json?tag=admin")); len(got) != 1 || got["/admin/users"] == nil {
+		t.Errorf("tag filtered paths = %v", got)
+	}
+	if got := paths(get("/openapi.json?prefix=/news")); len(got) != 1 || got["/news"] == nil {
+		t.Errorf("prefix filtered paths = %v", got)
+	}
+	if got := paths(get("/openapi/public.json")); len(got) != 1 || got["/news"] == nil {
+		t.Errorf("public document paths = %v", got)
+	}
+	// 命名子文档上的查询过滤与文档过滤同时生效
+	if got := paths(get("/openapi/public.json?tag=admin")); len(got) != 0 {
+		t.Errorf("combined filter paths = %v, want none", got)
+	}
+	if w := get("/openapi/admin.yaml"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/admin/users") {
+		t.Errorf("admin yaml document = %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEngine_OpenAPIVersion31(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{OpenAPIVersion: OpenAPIVersion31}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	doc := buildTestDoc(t, e)
+	if doc.OpenAPI != OpenAPIVersion31 || doc.JSONSchemaDialect == "" {
+		t.Fatalf("openapi = %q, dialect = %q", doc.OpenAPI, doc.JSONSchemaDialect)
+	}
+}
+
+func TestEngine_ExportOpenAPI(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{Title: "export"}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "api", "openapi.json")
+	if err := e.ExportOpenAPI(jsonPath, ""); err != nil {
+		t.Fatalf("export json: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read json: %v", err)
+	}
+	if m, err := parseResponse(data); err != nil || m["openapi"] != "3.0.3" {
+		t.Errorf("exported json = %s, err = %v", data, err)
+	}
+
+	yamlPath := filepath.Join(dir, "openapi.yml")
+	if err := e.ExportOpenAPI(yamlPath, ""); err != nil {
+		t.Fatalf("export yaml: %v", err)
+	}
+	data, _ = os.ReadFile(yamlPath)
+	if !strings.HasPrefix(string(data), "openapi: 3.0.3") {
+		t.Errorf("exported yaml = %s", data)
+	}
+
+	if err := e.ExportOpenAPI(filepath.Join(dir, "x"), "xml"); err == nil {
+		t.Error("expected unsupported format error")
+	}
+	if err := New(WithMode("test")).ExportOpenAPI(jsonPath, ""); err == nil {
+		t.Error("expected error when OpenAPI is disabled")
+	}
+}
+
+func TestEngine_ExportClient(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{Title: "client"}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+	e.API().POST("/users", Bind(func(c *Context, req *createUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Done()
+
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "user-sdk", "client.go")
+	if err := e.ExportClient(goPath); err != nil {
+		t.Fatalf("export go client: %v", err)
+	}
+	data, _ := os.ReadFile(goPath)
+	for _, want := range []string{
+		"package usersdk",
+		"func (c *Client) GetUsersByID(ctx context.Context, id string) (*UserResp, error)",
+		"func (c *Client) PostUsers(ctx context.Context, body *CreateUserReq) (*UserResp, error)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("go client missing %q\n%s", want, data)
+		}
+	}
+
+	tsPath := filepath.Join(dir, "api.ts")
+	if err := e.ExportClient(tsPath); err != nil {
+		t.Fatalf("export ts client: %v", err)
+	}
+	data, _ = os.ReadFile(tsPath)
+	if !strings.Contains(string(data), "async getUsersByID(id: string, init?: RequestInit): Promise<UserResp>") {
+		t.Errorf("ts client = %s", data)
+	}
+
+	if err := e.ExportClient(filepath.Join(dir, "client.py")); err == nil {
+		t.Error("expected unsupported client file error")
+	}
+}
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (c circle) area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type square struct {
+	Kind string  `json:"kind"`
+	Side float64 `json:"side"`
+}
+
+func (s square) area() float64 { return s.Side * s.Side }
+
+type drawingResp struct {
+	Shapes []shape `json:"shapes"`
+}
+
+func TestOpenAPIConfig_Unions(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{
+		Unions: []Union{OneOf[shape]("kind", Variant("circle", circle{}), Variant("square", square{}))},
+	}))
+	e.API().GET("/drawing", func(c *Context) { c.OK(drawingResp{}) }).Response(drawingResp{}).Done()
+
+	doc := buildTestDoc(t, e)
+	union := doc.Components.Schemas["github.com.tokmz.qi.shape.Response"]
+	if union == nil || len(union.OneOf) != 2 || union.Discriminator == nil || len(union.Discriminator.Mapping) != 2 {
+		t.Fatalf("unexpected union schema: %#v", union)
+	}
+}
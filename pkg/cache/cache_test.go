@@ -108,6 +108,42 @@ func TestNew_WithTracing(t *testing.T) {
 	}
 }
 
+func TestHealthChecker_Memory(t *testing.T) {
+	c, err := New(&Config{
+		Driver:         DriverMemory,
+		Memory:         &MemoryConfig{MaxSize: 100},
+		Penetration:    &PenetrationConfig{},
+		TracingEnabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	h := NewHealthChecker(c)
+	if h.Name() != "cache" {
+		t.Fatalf("want cache, got %s", h.Name())
+	}
+	// 纯内存缓存没有外部连接，始终健康
+	if err := h.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHealthChecker_Redis(t *testing.T) {
+	c := newTestRedis(t)
+	// 装饰器透传 Ping
+	g, _ := newPenetrationGuard(c, &PenetrationConfig{}, nil)
+	if err := NewHealthChecker(newTracingCache(g)).Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+	if err := NewHealthChecker(c).Check(context.Background()); err == nil {
+		t.Fatal("want error after close")
+	}
+}
+
 // ===== Config setDefaults =====
 
 func TestSetDefaults_Serializer(t *testing.T) {
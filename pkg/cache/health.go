@@ -0,0 +1,39 @@
+package cache
+
+import "context"
+
+// Pinger 可检查后端连接的缓存（Redis、多级缓存及其装饰器）
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping 检查缓存后端连接，纯内存缓存没有外部连接，直接返回 nil
+func Ping(ctx context.Context, c Cache) error {
+	if p, ok := c.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// HealthChecker 缓存健康检查，实现 qi.HealthChecker
+//
+// 示例：
+//
+//	app := qi.New(qi.WithHealth(nil))
+//	app.AddHealthChecker(cache.NewHealthChecker(c))
+type HealthChecker struct {
+	cache Cache
+}
+
+// NewHealthChecker 创建缓存健康检查
+func NewHealthChecker(c Cache) *HealthChecker {
+	return &HealthChecker{cache: c}
+}
+
+// Name 返回检查项名称
+func (h *HealthChecker) Name() string { return "cache" }
+
+// Check 检查 Redis 连接
+func (h *HealthChecker) Check(ctx context.Context) error {
+	return Ping(ctx, h.cache)
+}
@@ -160,3 +160,7 @@ func (c *multiLevelCache) Close() error {
 	_ = c.l1.Close()
 	return c.l2.Close()
 }
+
+func (c *multiLevelCache) Ping(ctx context.Context) error {
+	return c.l2.Ping(ctx)
+}
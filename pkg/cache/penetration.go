@@ -188,3 +188,7 @@ func (g *penetrationGuard) Flush(ctx context.Context) error {
 func (g *penetrationGuard) Close() error {
 	return g.inner.Close()
 }
+
+func (g *penetrationGuard) Ping(ctx context.Context) error {
+	return Ping(ctx, g.inner)
+}
@@ -249,6 +249,10 @@ func (c *redisCache) Close() error {
 	return c.client.Close()
 }
 
+func (c *redisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
 // NewLocker 创建基于 Redis 的分布式锁
 func NewLocker(cfg *RedisConfig, prefix string) (Locker, error) {
 	ser := JSONSerializer{}
@@ -172,3 +172,8 @@ func (t *tracingCache) Flush(ctx context.Context) error {
 func (t *tracingCache) Close() error {
 	return t.inner.Close()
 }
+
+// Ping 不创建 span，避免健康检查产生大量追踪数据
+func (t *tracingCache) Ping(ctx context.Context) error {
+	return Ping(ctx, t.inner)
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Ping 检查主库以及读写分离中所有从库的连接
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("primary: %w", err)
+	}
+
+	for _, plugin := range db.Config.Plugins {
+		resolver, ok := plugin.(*dbresolver.DBResolver)
+		if !ok {
+			continue
+		}
+		n := 0
+		return resolver.Call(func(pool gorm.ConnPool) error {
+			// 未配置 Sources 时主库连接池也在其中，已检查过
+			if pool == db.Config.ConnPool || pool == gorm.ConnPool(sqlDB) {
+				return nil
+			}
+			n++
+			conn, err := poolDB(pool)
+			if err != nil {
+				return fmt.Errorf("replica %d: %w", n, err)
+			}
+			if err := conn.PingContext(ctx); err != nil {
+				return fmt.Errorf("replica %d: %w", n, err)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// poolDB 从连接池中取出 *sql.DB（兼容 PrepareStmt 模式）
+func poolDB(pool gorm.ConnPool) (*sql.DB, error) {
+	switch p := pool.(type) {
+	case *sql.DB:
+		return p, nil
+	case gorm.GetDBConnector:
+		return p.GetDBConn()
+	default:
+		return nil, fmt.Errorf("unsupported conn pool %T", pool)
+	}
+}
+
+// HealthChecker 数据库健康检查，实现 qi.HealthChecker
+//
+// 示例：
+//
+//	db, _ := database.New(cfg)
+//	app.AddHealthChecker(database.NewHealthChecker(db))
+type HealthChecker struct {
+	db *gorm.DB
+}
+
+// NewHealthChecker 创建数据库健康检查，配置了读写分离时同时检查所有从库
+func NewHealthChecker(db *gorm.DB) *HealthChecker {
+	return &HealthChecker{db: db}
+}
+
+// Name 返回检查项名称
+func (h *HealthChecker) Name() string { return "database" }
+
+// Check 检查主库和从库连接
+func (h *HealthChecker) Check(ctx context.Context) error {
+	return Ping(ctx, h.db)
+}
@@ -0,0 +1,228 @@
+// Package i18n 提供按语言区域组织的消息目录和 Accept-Language 协商。
+//
+// 消息分两类：按业务错误码索引的错误消息，以及按校验规则索引的字段消息。
+// 规则消息支持占位符 {param}（规则参数原文）和 {list}（以空格分隔的参数转为逗号分隔）。
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bundle 消息目录集合，可被多个 goroutine 并发使用。
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	codes    map[string]map[int]string    // locale -> code -> message
+	rules    map[string]map[string]string // locale -> rule -> template
+}
+
+// New 创建 Bundle，fallback 为无法协商时使用的语言区域，如 "zh-CN"。
+func New(fallback string) *Bundle {
+	return &Bundle{
+		fallback: Normalize(fallback),
+		codes:    make(map[string]map[int]string),
+		rules:    make(map[string]map[string]string),
+	}
+}
+
+// Fallback 返回默认语言区域。
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// AddCodes 为语言区域添加业务错误码消息，已存在的码会被覆盖。
+func (b *Bundle) AddCodes(locale string, messages map[int]string) *Bundle {
+	locale = Normalize(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := b.codes[locale]
+	if m == nil {
+		m = make(map[int]string, len(messages))
+		b.codes[locale] = m
+	}
+	for code, msg := range messages {
+		m[code] = msg
+	}
+	return b
+}
+
+// AddRules 为语言区域添加校验规则消息模板。
+// 规则名可带单位后缀以区分长度和元素个数，如 "min.length"、"min.items"，查找时优先匹配带后缀的规则。
+func (b *Bundle) AddRules(locale string, templates map[string]string) *Bundle {
+	locale = Normalize(locale)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := b.rules[locale]
+	if m == nil {
+		m = make(map[string]string, len(templates))
+		b.rules[locale] = m
+	}
+	for rule, tmpl := range templates {
+		m[rule] = tmpl
+	}
+	return b
+}
+
+// Locales 返回已注册消息的语言区域，按字母序排列。
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	seen := make(map[string]bool)
+	for l := range b.codes {
+		seen[l] = true
+	}
+	for l := range b.rules {
+		seen[l] = true
+	}
+	out := make([]string, 0, len(seen))
+	for l := range seen {
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Match 按候选语言区域的顺序返回第一个已注册的语言区域，
+// 精确匹配优先，其次匹配主语言（如 "zh-TW" 匹配 "zh-CN"）；都不匹配时返回 fallback。
+func (b *Bundle) Match(candidates ...string) string {
+	locales := b.Locales()
+	for _, c := range candidates {
+		c = Normalize(c)
+		if c == "" {
+			continue
+		}
+		for _, l := range locales {
+			if l == c {
+				return l
+			}
+		}
+		base := baseLanguage(c)
+		for _, l := range locales {
+			if baseLanguage(l) == base {
+				return l
+			}
+		}
+	}
+	return b.fallback
+}
+
+// Code 返回语言区域下业务错误码的消息。
+func (b *Bundle) Code(locale string, code int) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	msg, ok := b.codes[Normalize(locale)][code]
+	return msg, ok
+}
+
+// Localize 返回业务错误的本地化消息。
+// message 为空或等于任一语言区域中该错误码的消息时视为默认消息，替换为目标语言区域的消息；
+// 否则视为调用方自定义的消息（如 WithMessage），原样返回。
+func (b *Bundle) Localize(locale string, code int, message string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	target, ok := b.codes[Normalize(locale)][code]
+	if !ok {
+		return message
+	}
+	if message == "" {
+		return target
+	}
+	for _, m := range b.codes {
+		if m[code] == message {
+			return target
+		}
+	}
+	return message
+}
+
+// Rule 返回语言区域下校验规则的消息，unit 为单位后缀（"length"、"items" 或空）。
+func (b *Bundle) Rule(locale, rule, unit, param string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	m := b.rules[Normalize(locale)]
+	tmpl, ok := "", false
+	if unit != "" {
+		tmpl, ok = m[rule+"."+unit]
+	}
+	if !ok {
+		tmpl, ok = m[rule]
+	}
+	if !ok {
+		return "", false
+	}
+	return Render(tmpl, param), true
+}
+
+// Render 替换消息模板中的 {param} 和 {list} 占位符。
+func Render(tmpl, param string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	return strings.NewReplacer(
+		"{param}", param,
+		"{list}", strings.Join(strings.Fields(param), ", "),
+	).Replace(tmpl)
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言区域，忽略 q=0 和 "*"。
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		locale string
+		q      float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{Normalize(tag), q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.locale
+	}
+	return out
+}
+
+// Normalize 规范化语言区域写法：主语言小写，地区大写，下划线转连字符，如 "zh_cn" → "zh-CN"。
+func Normalize(locale string) string {
+	locale = strings.TrimSpace(strings.ReplaceAll(locale, "_", "-"))
+	if locale == "" {
+		return ""
+	}
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i]) // 地区，如 CN
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:]) // 文字，如 Hans
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("en-US;q=0.8, zh_cn, *;q=0.1, fr;q=0, ja;q=0.9")
+	want := []string{"zh-CN", "ja", "en-US"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+	if got := ParseAcceptLanguage(""); len(got) != 0 {
+		t.Errorf("empty header = %v", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"zh_cn":      "zh-CN",
+		"EN-us":      "en-US",
+		"zh-hans-cn": "zh-Hans-CN",
+		" en ":       "en",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBundleMatch(t *testing.T) {
+	b := New("zh-CN").
+		AddCodes("zh-CN", map[int]string{1: "失败"}).
+		AddCodes("en", map[int]string{1: "failed"})
+
+	tests := []struct {
+		candidates []string
+		want       string
+	}{
+		{[]string{"en-GB"}, "en"},
+		{[]string{"zh-TW", "en"}, "zh-CN"},
+		{[]string{"fr", "en"}, "en"},
+		{[]string{"fr"}, "zh-CN"},
+		{nil, "zh-CN"},
+	}
+	for _, tt := range tests {
+		if got := b.Match(tt.candidates...); got != tt.want {
+			t.Errorf("Match(%v) = %q, want %q", tt.candidates, got, tt.want)
+		}
+	}
+}
+
+func TestBundleLocalize(t *testing.T) {
+	b := New("en").
+		AddCodes("en", map[int]string{404: "not found"}).
+		AddCodes("zh-CN", map[int]string{404: "资源不存在"})
+
+	if got := b.Localize("zh-CN", 404, "not found"); got != "资源不存在" {
+		t.Errorf("default message = %q", got)
+	}
+	if got := b.Localize("en", 404, "资源不存在"); got != "not found" {
+		t.Errorf("message from another locale = %q", got)
+	}
+	if got := b.Localize("zh-CN", 404, "user 42 not found"); got != "user 42 not found" {
+		t.Errorf("custom message should be kept, got %q", got)
+	}
+	if got := b.Localize("zh-CN", 500, "boom"); got != "boom" {
+		t.Errorf("unknown code = %q", got)
+	}
+}
+
+func TestBundleRule(t *testing.T) {
+	b := New("en").AddRules("en", map[string]string{
+		"min":        "must be >= {param}",
+		"min.length": "length must be >= {param}",
+		"oneof":      "must be one of [{list}]",
+	})
+
+	tests := []struct {
+		rule, unit, param, want string
+	}{
+		{"min", "length", "3", "length must be >= 3"},
+		{"min", "items", "3", "must be >= 3"},
+		{"min", "", "3", "must be >= 3"},
+		{"oneof", "", "red green", "must be one of [red, green]"},
+	}
+	for _, tt := range tests {
+		got, ok := b.Rule("en", tt.rule, tt.unit, tt.param)
+		if !ok || got != tt.want {
+			t.Errorf("Rule(%q, %q, %q) = %q, %v, want %q", tt.rule, tt.unit, tt.param, got, ok, tt.want)
+		}
+	}
+	if _, ok := b.Rule("en", "email", "", ""); ok {
+		t.Error("unknown rule should not match")
+	}
+}
@@ -0,0 +1,53 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger 可检查连接状态的生产者或消费者（所有内置驱动均已实现）
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ping 检查连接状态，未实现 Pinger 时视为正常
+func ping(ctx context.Context, v any) error {
+	if p, ok := v.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// HealthChecker 消息队列健康检查，实现 qi.HealthChecker
+//
+// 示例：
+//
+//	producer, consumer, _ := mq.New(cfg)
+//	app.AddHealthChecker(mq.NewHealthChecker(producer, consumer))
+type HealthChecker struct {
+	producer Producer
+	consumer Consumer
+}
+
+// NewHealthChecker 创建消息队列健康检查，producer 和 consumer 可以为 nil
+func NewHealthChecker(producer Producer, consumer Consumer) *HealthChecker {
+	return &HealthChecker{producer: producer, consumer: consumer}
+}
+
+// Name 返回检查项名称
+func (h *HealthChecker) Name() string { return "mq" }
+
+// Check 检查生产者和消费者的连接状态
+func (h *HealthChecker) Check(ctx context.Context) error {
+	if h.producer != nil {
+		if err := ping(ctx, h.producer); err != nil {
+			return fmt.Errorf("producer: %w", err)
+		}
+	}
+	if h.consumer != nil {
+		if err := ping(ctx, h.consumer); err != nil {
+			return fmt.Errorf("consumer: %w", err)
+		}
+	}
+	return nil
+}
@@ -10,13 +10,15 @@ import (
 
 // kafkaProducer Kafka 生产者
 type kafkaProducer struct {
+	client   sarama.Client // 底层客户端，用于检查 broker 连接
 	producer sarama.SyncProducer
 	onError  func(error)
 }
 
 // kafkaConsumer Kafka 消费者
 type kafkaConsumer struct {
-	client        sarama.ConsumerGroup
+	client        sarama.Client // 底层客户端，用于检查 broker 连接
+	group         sarama.ConsumerGroup
 	consumerGroup string
 	onError       func(error)
 	shutdown      chan struct{}
@@ -56,11 +58,16 @@ func newKafka(cfg *KafkaConfig) (Producer, Consumer, error) {
 		producerConfig.Producer.Compression = sarama.CompressionNone
 	}
 
-	// 创建 Producer
-	producer, err := sarama.NewSyncProducer(cfg.Brokers, producerConfig)
+	// 创建 Producer（基于独立客户端，便于检查连接状态）
+	producerClient, err := sarama.NewClient(cfg.Brokers, producerConfig)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create kafka producer: %w", err)
 	}
+	producer, err := sarama.NewSyncProducerFromClient(producerClient)
+	if err != nil {
+		producerClient.Close()
+		return nil, nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	}
 
 	// Consumer 配置
 	consumerConfig := sarama.NewConfig()
@@ -83,17 +90,27 @@ func newKafka(cfg *KafkaConfig) (Producer, Consumer, error) {
 	}
 
 	// 创建 Consumer Group
-	client, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.ConsumerGroup, consumerConfig)
+	consumerClient, err := sarama.NewClient(cfg.Brokers, consumerConfig)
 	if err != nil {
 		producer.Close()
+		producerClient.Close()
+		return nil, nil, fmt.Errorf("failed to create kafka consumer group: %w", err)
+	}
+	group, err := sarama.NewConsumerGroupFromClient(cfg.ConsumerGroup, consumerClient)
+	if err != nil {
+		consumerClient.Close()
+		producer.Close()
+		producerClient.Close()
 		return nil, nil, fmt.Errorf("failed to create kafka consumer group: %w", err)
 	}
 
 	return &kafkaProducer{
+			client:   producerClient,
 			producer: producer,
 			onError:  cfg.OnError,
 		}, &kafkaConsumer{
-			client:        client,
+			client:        consumerClient,
+			group:         group,
 			consumerGroup: cfg.ConsumerGroup,
 			onError:       cfg.OnError,
 			shutdown:      make(chan struct{}),
@@ -118,7 +135,16 @@ func (p *kafkaProducer) Publish(ctx context.Context, topic string, msg []byte) e
 
 // Close 关闭生产者
 func (p *kafkaProducer) Close() error {
-	return p.producer.Close()
+	err := p.producer.Close()
+	if e := p.client.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// Ping 检查 broker 连接
+func (p *kafkaProducer) Ping(context.Context) error {
+	return kafkaStatus(p.client)
 }
 
 // Subscribe 订阅主题
@@ -133,7 +159,7 @@ func (c *kafkaConsumer) Subscribe(ctx context.Context, topic string, handler fun
 
 	// 消费消息（Consume 会自动处理 rebalance 和重连）
 	// 注意：Consume 是阻塞调用，只有在 ctx 取消或发生致命错误时才会返回
-	err := c.client.Consume(ctx, []string{topic}, consumerHandler)
+	err := c.group.Consume(ctx, []string{topic}, consumerHandler)
 	if err != nil {
 		c.handleError(fmt.Errorf("consumer error: %w", err))
 		return err
@@ -146,7 +172,27 @@ func (c *kafkaConsumer) Subscribe(ctx context.Context, topic string, handler fun
 func (c *kafkaConsumer) Close() error {
 	close(c.shutdown)
 	<-c.done
-	return c.client.Close()
+	err := c.group.Close()
+	if e := c.client.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// Ping 检查 broker 连接
+func (c *kafkaConsumer) Ping(context.Context) error {
+	return kafkaStatus(c.client)
+}
+
+// kafkaStatus 检查客户端是否关闭，并通过获取 controller 确认 broker 可达
+func kafkaStatus(client sarama.Client) error {
+	if client.Closed() {
+		return fmt.Errorf("kafka client closed")
+	}
+	if _, err := client.Controller(); err != nil {
+		return fmt.Errorf("kafka controller unavailable: %w", err)
+	}
+	return nil
 }
 
 // handleError 处理错误
@@ -2,6 +2,7 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -21,6 +22,10 @@ func TestRedisProducerConsumer(t *testing.T) {
 	defer producer.Close()
 	defer consumer.Close()
 
+	if err := NewHealthChecker(newTracingProducer(producer), consumer).Check(context.Background()); err != nil {
+		t.Fatalf("健康检查失败: %v", err)
+	}
+
 	topic := "test-topic"
 	testMsg := []byte("hello world")
 	received := make(chan []byte, 1)
@@ -91,3 +96,23 @@ func TestNew_NilConfig(t *testing.T) {
 		t.Fatal("期望错误，但成功了")
 	}
 }
+
+type stubProducer struct {
+	Producer
+	err error
+}
+
+func (p stubProducer) Ping(context.Context) error { return p.err }
+
+func TestHealthChecker(t *testing.T) {
+	h := NewHealthChecker(nil, nil)
+	if h.Name() != "mq" || h.Check(context.Background()) != nil {
+		t.Fatal("空检查应视为正常")
+	}
+
+	errDown := errors.New("connection closed")
+	err := NewHealthChecker(newTracingProducer(stubProducer{err: errDown}), nil).Check(context.Background())
+	if !errors.Is(err, errDown) || err.Error() != "producer: connection closed" {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -31,6 +31,7 @@ type rabbitmqConsumer struct {
 	// 重连配置
 	url           string
 	reconnectWait time.Duration
+	connMu        sync.RWMutex // 保护重连时替换 conn/channel，供 Ping 并发读取
 
 	// 优雅关闭
 	shutdown  chan struct{}
@@ -148,6 +149,11 @@ func (p *rabbitmqProducer) Close() error {
 	return p.conn.Close()
 }
 
+// Ping 检查连接和通道状态
+func (p *rabbitmqProducer) Ping(context.Context) error {
+	return rabbitmqStatus(p.conn, p.channel)
+}
+
 // Subscribe 订阅消息（带自动重连）
 func (c *rabbitmqConsumer) Subscribe(ctx context.Context, topic string, handler func([]byte) error) error {
 	defer close(c.done)
@@ -307,8 +313,10 @@ func (c *rabbitmqConsumer) reconnect() error {
 		return fmt.Errorf("failed to set qos: %w", err)
 	}
 
+	c.connMu.Lock()
 	c.conn = conn
 	c.channel = channel
+	c.connMu.Unlock()
 	return nil
 }
 
@@ -343,3 +351,21 @@ func (c *rabbitmqConsumer) Close() error {
 	})
 	return err
 }
+
+// Ping 检查连接和通道状态，重连期间返回错误
+func (c *rabbitmqConsumer) Ping(context.Context) error {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return rabbitmqStatus(c.conn, c.channel)
+}
+
+// rabbitmqStatus 检查连接和通道是否已关闭
+func rabbitmqStatus(conn *amqp.Connection, channel *amqp.Channel) error {
+	if conn == nil || conn.IsClosed() {
+		return fmt.Errorf("rabbitmq connection closed")
+	}
+	if channel == nil || channel.IsClosed() {
+		return fmt.Errorf("rabbitmq channel closed")
+	}
+	return nil
+}
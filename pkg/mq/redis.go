@@ -109,6 +109,11 @@ func (p *redisProducer) Close() error {
 	return p.client.Close()
 }
 
+// Ping 检查 Redis 连接
+func (p *redisProducer) Ping(ctx context.Context) error {
+	return p.client.Ping(ctx).Err()
+}
+
 // Subscribe 订阅消息
 func (c *redisConsumer) Subscribe(ctx context.Context, topic string, handler func([]byte) error) error {
 	// 创建消费组（如果不存在）
@@ -312,3 +317,8 @@ func (c *redisConsumer) Close() error {
 	})
 	return err
 }
+
+// Ping 检查 Redis 连接
+func (c *redisConsumer) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
@@ -67,6 +67,11 @@ func (t *tracingProducer) Close() error {
 	return t.producer.Close()
 }
 
+// Ping 检查连接状态（不创建 span）
+func (t *tracingProducer) Ping(ctx context.Context) error {
+	return ping(ctx, t.producer)
+}
+
 // Subscribe 订阅消息（带追踪）
 func (t *tracingConsumer) Subscribe(ctx context.Context, topic string, handler func([]byte) error) error {
 	// 包装 handler，为每条消息创建 span
@@ -118,3 +123,8 @@ func (t *tracingConsumer) Subscribe(ctx context.Context, topic string, handler f
 func (t *tracingConsumer) Close() error {
 	return t.consumer.Close()
 }
+
+// Ping 检查连接状态（不创建 span）
+func (t *tracingConsumer) Ping(ctx context.Context) error {
+	return ping(ctx, t.consumer)
+}
@@ -0,0 +1,70 @@
+package qi
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// MIMEProblemJSON RFC 7807 错误响应的媒体类型。
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem RFC 7807 错误响应体，code、trace_id 和 errors 为扩展成员。
+type Problem struct {
+	Type     string           `json:"type"`               // 问题类型 URI，默认 "about:blank"
+	Title    string           `json:"title"`              // HTTP 状态码的标准描述
+	Status   int              `json:"status"`             // HTTP 状态码
+	Detail   string           `json:"detail,omitempty"`   // 错误消息
+	Instance string           `json:"instance,omitempty"` // 请求路径
+	Code     int              `json:"code"`               // 业务码
+	TraceID  string           `json:"trace_id,omitempty"` // 链路追踪 ID
+	Errors   ValidationErrors `json:"errors,omitempty"`   // 字段级校验错误
+}
+
+// MediaType 返回 application/problem+json，Problem 不参与 Accept 协商。
+func (*Problem) MediaType() string {
+	return MIMEProblemJSON
+}
+
+// ProblemRenderer RFC 7807 渲染器：Fail、panic 恢复和绑定失败输出 application/problem+json，
+// 成功响应沿用 DefaultRenderer 的统一响应。
+//
+// 示例：
+//
+//	qi.New(qi.WithResponseRenderer(qi.ProblemRenderer{TypeBaseURI: "https://api.example.com/problems/"}))
+type ProblemRenderer struct {
+	DefaultRenderer
+	TypeBaseURI string // 问题类型 URI 前缀，type 为前缀 + 业务码；为空时 type 为 "about:blank"
+}
+
+// Render 实现 ResponseRenderer。
+func (r ProblemRenderer) Render(c *Context, res Result) any {
+	if res.Success() {
+		return r.DefaultRenderer.Render(c, res)
+	}
+	p := &Problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(res.Status),
+		Status:  res.Status,
+		Detail:  res.Message,
+		Code:    res.Code,
+		TraceID: res.TraceID,
+	}
+	if r.TypeBaseURI != "" {
+		p.Type = r.TypeBaseURI + strconv.Itoa(res.Code)
+	}
+	if c != nil {
+		p.Instance = c.Request().URL.Path
+	}
+	if fields, ok := fieldErrors(res.Err); ok {
+		p.Errors = fields
+	}
+	return p
+}
+
+// Envelope 实现 ResponseRenderer，错误响应在文档中为 Problem。
+func (r ProblemRenderer) Envelope() *Envelope {
+	env := r.DefaultRenderer.Envelope()
+	env.ErrorType = Problem{}
+	env.ErrorMediaType = MIMEProblemJSON
+	return env
+}
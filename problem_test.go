@@ -0,0 +1,83 @@
+package qi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProblemRenderer(t *testing.T) {
+	e := New(WithMode("test"), WithResponseRenderer(ProblemRenderer{TypeBaseURI: "https://example.com/problems/"}))
+	e.GET("/ok", func(c *Context) { c.OK("pong") })
+	e.GET("/users/:id", func(c *Context) { c.Fail(ErrNotFound) })
+	e.POST("/users", Bind(func(c *Context, req *createUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	}).Handler)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Data != "pong" {
+		t.Fatalf("success body = %s, err = %v", w.Body.String(), err)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("success Content-Type = %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/users/7", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, MIMEProblemJSON) {
+		t.Errorf("error Content-Type = %q, want %s", ct, MIMEProblemJSON)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:     "https://example.com/problems/1004",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   ErrNotFound.Message,
+		Instance: "/users/7",
+		Code:     ErrNotFound.Code,
+	}
+	if w.Code != http.StatusNotFound || p.Type != want.Type || p.Title != want.Title || p.Status != want.Status ||
+		p.Detail != want.Detail || p.Instance != want.Instance || p.Code != want.Code {
+		t.Errorf("problem = %+v, want %+v", p, want)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/users", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	e.ServeHTTP(w, req)
+	p = Problem{}
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "name" || p.Errors[0].Rule != "required" {
+		t.Errorf("bind problem = %s", w.Body.String())
+	}
+}
+
+func TestProblemRenderer_OpenAPI(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}), WithResponseRenderer(ProblemRenderer{}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Errors(ErrNotFound).Done()
+
+	doc := buildTestDoc(t, e)
+
+	op := doc.Paths["/users/{id}"].Get
+	if got := op.Responses["200"].Content["application/json"].Schema; !strings.HasPrefix(got.Ref, "#/components/schemas/Response_") {
+		t.Errorf("200 schema = %#v, want Response wrapper", got)
+	}
+	for _, status := range []string{"400", "404"} {
+		content := op.Responses[status].Content
+		media := content[MIMEProblemJSON]
+		if len(content) != 1 || media == nil || media.Schema == nil || !strings.Contains(media.Schema.Ref, ".Problem") {
+			t.Errorf("%s content = %#v, want problem+json only", status, content)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package qi
+
+import "github.com/gin-gonic/gin"
+
+// gin.Context 中存放响应渲染器的键。
+const rendererKey = "qi.renderer"
+
+// Result 统一响应的内容，由 ResponseRenderer 渲染为响应体。
+type Result struct {
+	Status  int    // HTTP 状态码
+	Code    int    // 业务码，成功响应为 0，由渲染器替换为自身的成功码
+	Message string // 提示信息，成功响应未指定时为空
+	Data    any    // 业务数据；字段级校验错误为 {"errors": ValidationErrors}
+	TraceID string // 链路追踪 ID
+	Err     error  // 错误响应的原始错误，成功响应为 nil
+}
+
+// Success 判断是否为成功响应。
+func (r Result) Success() bool {
+	return r.Err == nil
+}
+
+// Envelope 描述统一响应的包装结构，用于 OpenAPI 文档和客户端生成。
+type Envelope struct {
+	Name         string // 组件名，如 "Response"
+	Type         any    // 包装结构体样例，如 Response{}
+	CodeField    string // 业务码字段，如 "code"
+	MessageField string // 消息字段，如 "message"
+	DataField    string // 业务数据字段，如 "data"
+	TraceIDField string // 追踪 ID 字段，可为空
+	SuccessCode  int    // 成功业务码
+
+	// 错误响应使用独立结构时设置（如 RFC 7807 的 Problem），错误响应在文档中原样输出，不经过包装
+	ErrorType      any    // 错误响应结构体样例
+	ErrorMediaType string // 错误响应的媒体类型，如 "application/problem+json"
+}
+
+// ResponseRenderer 统一响应渲染器，决定 OK / Fail / Page、panic 恢复和绑定失败的响应结构。
+//
+// 示例：
+//
+//	type wechatRenderer struct{}
+//
+//	func (wechatRenderer) Render(c *qi.Context, r qi.Result) any {
+//		return WechatResp{ErrCode: r.Code, ErrMsg: r.Message, Result: r.Data}
+//	}
+//
+//	func (wechatRenderer) Envelope() *qi.Envelope {
+//		return &qi.Envelope{Name: "WechatResp", Type: WechatResp{}, CodeField: "errcode",
+//			MessageField: "errmsg", DataField: "result"}
+//	}
+type ResponseRenderer interface {
+	// Render 返回响应体，由 Context 按 Accept 协商编码，返回 nil 时只写状态码；
+	// 响应体实现 MediaType() string 时（如 *Problem）不参与协商，以该媒体类型输出 JSON。
+	// 生成 OpenAPI 错误示例时 c 为 nil。
+	Render(c *Context, r Result) any
+	// Envelope 返回包装结构描述，nil 表示业务数据不包装，OpenAPI 文档和客户端直接使用业务数据类型。
+	Envelope() *Envelope
+}
+
+// WithResponseRenderer 设置统一响应渲染器，默认为 DefaultRenderer{}。
+func WithResponseRenderer(r ResponseRenderer) Option {
+	return func(c *Config) {
+		c.renderer = r
+	}
+}
+
+// DefaultRenderer 默认渲染器，输出 Response：{code, message, data, trace_id}。
+type DefaultRenderer struct {
+	SuccessCode    int    // 成功业务码，默认 0
+	SuccessMessage string // 成功响应的默认消息，默认 "success"
+}
+
+// Render 实现 ResponseRenderer。
+func (r DefaultRenderer) Render(_ *Context, res Result) any {
+	code, msg := res.Code, res.Message
+	if res.Success() {
+		code = r.SuccessCode
+		if msg == "" {
+			msg = r.SuccessMessage
+		}
+		if msg == "" {
+			msg = "success"
+		}
+	}
+	resp := NewResponse(code, msg, res.Data)
+	resp.SetTraceID(res.TraceID)
+	return resp
+}
+
+// Envelope 实现 ResponseRenderer。
+func (r DefaultRenderer) Envelope() *Envelope {
+	return &Envelope{
+		Name:         "Response",
+		Type:         Response{},
+		CodeField:    "code",
+		MessageField: "message",
+		DataField:    "data",
+		TraceIDField: "trace_id",
+		SuccessCode:  r.SuccessCode,
+	}
+}
+
+// mediaTyper 自带媒体类型的响应体。
+type mediaTyper interface {
+	MediaType() string
+}
+
+// rendererMiddleware 将响应渲染器写入 gin.Context。
+func rendererMiddleware(r ResponseRenderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(rendererKey, r)
+		c.Next()
+	}
+}
+
+// renderer 返回 Engine 配置的响应渲染器。
+func (e *Engine) renderer() ResponseRenderer {
+	if e.cfg.renderer != nil {
+		return e.cfg.renderer
+	}
+	return DefaultRenderer{}
+}
+
+// renderer 返回当前请求使用的响应渲染器。
+func (c *Context) renderer() ResponseRenderer {
+	if v, ok := c.ctx.Get(rendererKey); ok {
+		if r, ok := v.(ResponseRenderer); ok {
+			return r
+		}
+	}
+	return DefaultRenderer{}
+}
@@ -0,0 +1,121 @@
+package qi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// wechatResp 自定义响应结构，模拟 {errcode, errmsg, result} 风格的接口约定。
+type wechatResp struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Result  any    `json:"result,omitempty"`
+}
+
+type wechatRenderer struct{}
+
+func (wechatRenderer) Render(_ *Context, r Result) any {
+	if r.Success() {
+		return wechatResp{ErrMsg: "ok", Result: r.Data}
+	}
+	return wechatResp{ErrCode: r.Code, ErrMsg: r.Message, Result: r.Data}
+}
+
+func (wechatRenderer) Envelope() *Envelope {
+	return &Envelope{Name: "WechatResp", Type: wechatResp{}, CodeField: "errcode", MessageField: "errmsg", DataField: "result"}
+}
+
+func TestResponseRenderer_Custom(t *testing.T) {
+	e := New(WithMode("test"), WithResponseRenderer(wechatRenderer{}))
+	e.GET("/ok", func(c *Context) { c.OK(userResp{ID: 1}) })
+	e.GET("/fail", func(c *Context) { c.Fail(ErrNotFound) })
+	e.GET("/panic", func(c *Context) { panic("boom") })
+	e.POST("/bind", Bind(func(c *Context, req *createUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	}).Handler)
+
+	tests := []struct {
+		method, path, body string
+		status, code       int
+	}{
+		{"GET", "/ok", "", http.StatusOK, 0},
+		{"GET", "/fail", "", http.StatusNotFound, ErrNotFound.Code},
+		{"GET", "/panic", "", http.StatusInternalServerError, ErrServer.Code},
+		{"POST", "/bind", "{}", http.StatusBadRequest, ErrBadRequest.Code},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		e.ServeHTTP(w, req)
+
+		var got map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", tt.method, tt.path, w.Body.String(), err)
+		}
+		if w.Code != tt.status || got["errcode"] != float64(tt.code) || got["code"] != nil {
+			t.Errorf("%s %s: status = %d, body = %v", tt.method, tt.path, w.Code, got)
+		}
+	}
+}
+
+func TestDefaultRenderer_SuccessCode(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}), WithResponseRenderer(DefaultRenderer{SuccessCode: 200, SuccessMessage: "ok"}))
+	e.GET("/ok", func(c *Context) { c.OK(nil) })
+	e.GET("/msg", func(c *Context) { c.OK(nil, "created") })
+
+	for path, msg := range map[string]string{"/ok": "ok", "/msg": "created"} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Code != 200 || resp.Message != msg {
+			t.Errorf("GET %s: body = %+v, want code 200 message %q", path, resp, msg)
+		}
+	}
+
+	_, cfg, err := e.clientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Envelope == nil || cfg.Envelope.SuccessCode != 200 {
+		t.Errorf("client envelope = %+v, want success code 200", cfg.Envelope)
+	}
+}
+
+func TestResponseRenderer_OpenAPIEnvelope(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}), WithResponseRenderer(wechatRenderer{}))
+	e.API().GET("/users/:id", Bind(func(c *Context, req *getUserReq) (*userResp, error) {
+		return &userResp{}, nil
+	})).Errors(ErrNotFound).Done()
+
+	doc := buildTestDoc(t, e)
+
+	if doc.Components.Schemas["WechatResp"] == nil || doc.Components.Schemas["Response"] != nil {
+		t.Fatalf("envelope components = %v, want WechatResp only", doc.Components.Schemas)
+	}
+	op := doc.Paths["/users/{id}"].Get
+	if got := op.Responses["200"].Content["application/json"].Schema; !strings.HasPrefix(got.Ref, "#/components/schemas/WechatResp_") {
+		t.Errorf("200 schema = %#v, want WechatResp wrapper", got)
+	}
+	example := op.Responses["404"].Content["application/json"].Examples["1004"]
+	if example == nil {
+		t.Fatalf("404 examples = %v", op.Responses["404"].Content["application/json"].Examples)
+	}
+	if v, ok := example.Value.(wechatResp); !ok || v.ErrCode != ErrNotFound.Code {
+		t.Errorf("404 example = %#v, want wechatResp", example.Value)
+	}
+
+	_, cfg, err := e.clientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env := cfg.Envelope; env == nil || env.Component != "WechatResp" || env.CodeField != "errcode" || env.DataField != "result" {
+		t.Errorf("client envelope = %+v", cfg.Envelope)
+	}
+}
@@ -1,11 +1,14 @@
 package qi
 
+import "encoding/xml"
+
 // Response 响应结构体
 type Response struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Data    any    `json:"data"`
-	TraceID string `json:"trace_id,omitempty"`
+	XMLName xml.Name `json:"-" xml:"response" codec:"-"`
+	Code    int      `json:"code" xml:"code" openapi:"required" desc:"业务码，0 表示成功" example:"0"`
+	Message string   `json:"message" xml:"message" openapi:"required" desc:"提示信息" example:"success"`
+	Data    any      `json:"data" xml:"data,omitempty" desc:"业务数据"`
+	TraceID string   `json:"trace_id,omitempty" xml:"trace_id,omitempty" desc:"链路追踪 ID"`
 }
 
 // NewResponse 创建新的响应
@@ -34,6 +34,7 @@ type RouterGroup struct {
 	engine      *Engine
 	prefix      string
 	middlewares HandlersChain
+	api         groupAPIDefaults // OpenAPI 默认值，子分组继承
 }
 
 // Use 为当前分组追加中间件。
@@ -44,10 +45,13 @@ func (r *RouterGroup) Use(handlers ...HandlerFunc) {
 // Group 创建子分组，并继承当前分组中间件。
 func (r *RouterGroup) Group(prefix string, handlers ...HandlerFunc) *RouterGroup {
 	inherited := append(cloneHandlers(r.middlewares), handlers...)
+	api := r.api.clone()
+	api.securityInherited = true
 	return &RouterGroup{
 		engine:      r.engine,
 		prefix:      joinPaths(r.prefix, prefix),
 		middlewares: inherited,
+		api:         api,
 	}
 }
 
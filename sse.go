@@ -0,0 +1,266 @@
+package qi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MIMEEventStream SSE 响应的媒体类型。
+const MIMEEventStream = "text/event-stream"
+
+// defaultSSEHeartbeat SSE 默认心跳间隔。
+const defaultSSEHeartbeat = 15 * time.Second
+
+// Event SSE 事件。
+type Event struct {
+	ID    string        // 事件 ID，客户端重连时通过 Last-Event-ID 请求头回传
+	Event string        // 事件类型，为空时客户端按 message 处理
+	Data  any           // 事件数据，string / []byte 原样输出，其他类型编码为 JSON
+	Retry time.Duration // 客户端重连间隔，为 0 时不输出
+}
+
+// SSEOption SSE 选项。
+type SSEOption func(*sseConfig)
+
+type sseConfig struct {
+	heartbeat time.Duration
+}
+
+// WithSSEHeartbeat 设置心跳间隔（默认 15s），空闲时发送注释行保持连接，<= 0 时关闭心跳。
+func WithSSEHeartbeat(d time.Duration) SSEOption {
+	return func(c *sseConfig) {
+		c.heartbeat = d
+	}
+}
+
+// SSE 以 text/event-stream 推送事件，fn 返回或 ctx 结束、客户端断开时结束推送。
+// 客户端断开后 emit 返回错误，fn 应据此退出。每次写入后立即 flush，
+// 并按 Config.WriteTimeout 顺延写超时，长连接不会被服务端写超时中断。
+// fn 返回错误或 panic 时，以 error 事件推送 ResponseRenderer 渲染的错误响应（含 trace_id）；
+// 响应已开始输出，返回的错误不会再写入统一响应。
+//
+// 示例：
+//
+//	e.GET("/chat", func(c *qi.Context) {
+//		_ = c.SSE(c, func(emit func(qi.Event) error) error {
+//			for chunk := range tokens {
+//				if err := emit(qi.Event{Event: "token", Data: chunk}); err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		})
+//	})
+func (c *Context) SSE(ctx context.Context, fn func(emit func(Event) error) error, opts ...SSEOption) (err error) {
+	cfg := sseConfig{heartbeat: defaultSSEHeartbeat}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	c.ctx.Header("Content-Type", MIMEEventStream)
+	c.ctx.Header("Cache-Control", "no-cache")
+	c.ctx.Header("Connection", "keep-alive")
+	c.ctx.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	w, cancel := c.streamWriter(ctx)
+	defer cancel()
+
+	emit := func(ev Event) error {
+		data, err := encodeEvent(ev)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if err := w.flushHeader(); err != nil {
+		return err
+	}
+
+	// 结束心跳后再推送 error 事件，保证 error 事件是流的最后一条
+	stopHeartbeat := func() {}
+	if cfg.heartbeat > 0 {
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(cfg.heartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-w.ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+						return
+					}
+				}
+			}
+		}()
+		var once sync.Once
+		stopHeartbeat = func() {
+			once.Do(func() {
+				close(done)
+				wg.Wait()
+			})
+		}
+	}
+	defer stopHeartbeat()
+
+	defer func() {
+		if r := recover(); r != nil {
+			stopHeartbeat()
+			_ = emit(Event{Event: "error", Data: c.renderError(ErrServer)})
+			panic(r)
+		}
+	}()
+	err = fn(emit)
+	stopHeartbeat()
+	if err != nil && w.ctx.Err() == nil {
+		_ = emit(Event{Event: "error", Data: c.renderError(err)})
+	}
+	return err
+}
+
+// Stream 以 contentType 流式输出响应（如 "application/x-ndjson"），每次 Write 后立即 flush。
+// ctx 结束或客户端断开后 Write 返回错误；写超时处理与 SSE 相同。
+//
+// 示例：
+//
+//	_ = c.Stream(c, "application/x-ndjson", func(w io.Writer) error {
+//		enc := json.NewEncoder(w)
+//		for row := range rows {
+//			if err := enc.Encode(row); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	})
+func (c *Context) Stream(ctx context.Context, contentType string, fn func(w io.Writer) error) error {
+	c.ctx.Header("Content-Type", contentType)
+	w, cancel := c.streamWriter(ctx)
+	defer cancel()
+	if err := w.flushHeader(); err != nil {
+		return err
+	}
+	return fn(w)
+}
+
+// renderError 按 ResponseRenderer 渲染错误响应，用于已开始输出的流。
+func (c *Context) renderError(err error) any {
+	res := c.failResult(err)
+	res.TraceID = c.traceID()
+	return c.renderer().Render(c, res)
+}
+
+// streamWriter 流式响应的写入器：并发安全，每次写入后 flush，写入前顺延写超时。
+type streamWriter struct {
+	c       *Context
+	ctx     context.Context
+	rc      *http.ResponseController
+	timeout time.Duration
+	mu      sync.Mutex
+}
+
+// streamWriter 创建流式写入器，ctx 为 nil 时使用请求的 context，请求结束时同时结束。
+func (c *Context) streamWriter(ctx context.Context) (*streamWriter, context.CancelFunc) {
+	if ctx == nil {
+		ctx = c.Context()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.Context(), cancel)
+	w := &streamWriter{c: c, ctx: ctx, rc: http.NewResponseController(c.ctx.Writer)}
+	if srv, ok := c.Context().Value(http.ServerContextKey).(*http.Server); ok {
+		w.timeout = srv.WriteTimeout
+	}
+	return w, func() {
+		stop()
+		cancel()
+	}
+}
+
+// flushHeader 写出 200 状态码和响应头。
+func (w *streamWriter) flushHeader() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	w.extendDeadline()
+	w.c.ctx.Status(http.StatusOK)
+	w.c.ctx.Writer.WriteHeaderNow()
+	w.c.ctx.Writer.Flush()
+	return nil
+}
+
+// Write 实现 io.Writer。
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	w.extendDeadline()
+	n, err := w.c.ctx.Writer.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.c.ctx.Writer.Flush()
+	return n, nil
+}
+
+// extendDeadline 按服务端 WriteTimeout 顺延写超时，不支持时忽略。
+func (w *streamWriter) extendDeadline() {
+	if w.timeout > 0 {
+		_ = w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	}
+}
+
+// encodeEvent 按 SSE 格式编码事件，多行数据拆分为多个 data 行。
+func encodeEvent(ev Event) ([]byte, error) {
+	var data string
+	switch v := ev.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("qi: encode event data: %w", err)
+		}
+		data = string(b)
+	}
+
+	var buf bytes.Buffer
+	if ev.ID != "" {
+		buf.WriteString("id: " + sanitizeEventField(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		buf.WriteString("event: " + sanitizeEventField(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// sanitizeEventField 去除单行字段中的换行，避免注入额外字段。
+func sanitizeEventField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
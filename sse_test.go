@@ -0,0 +1,160 @@
+package qi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContext_SSE(t *testing.T) {
+	e := New(WithMode("test"))
+	e.GET("/events", func(c *Context) {
+		c.Set("trace_id", "t-1")
+		_ = c.SSE(c, func(emit func(Event) error) error {
+			if err := emit(Event{ID: "1", Event: "user", Data: userResp{ID: 1, Name: "qi"}}); err != nil {
+				return err
+			}
+			if err := emit(Event{Data: "line1\nline2", Retry: 3 * time.Second}); err != nil {
+				return err
+			}
+			return ErrNotFound
+		}, WithSSEHeartbeat(0))
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != MIMEEventStream {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want := "id: 1\nevent: user\ndata: {\"id\":1,\"name\":\"qi\"}\n\n" +
+		"retry: 3000\ndata: line1\ndata: line2\n\n" +
+		"event: error\ndata: "
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(body, want) {
+		t.Fatalf("status = %d, body = %q", w.Code, body)
+	}
+	var resp Response
+	if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(body, want))), &resp); err != nil {
+		t.Fatalf("decode error event: %v, body = %q", err, body)
+	}
+	if resp.Code != ErrNotFound.Code || resp.TraceID != "t-1" {
+		t.Errorf("error event = %+v", resp)
+	}
+}
+
+func TestContext_SSE_HeartbeatAndPanic(t *testing.T) {
+	e := New(WithMode("test"))
+	e.GET("/events", func(c *Context) {
+		_ = c.SSE(c, func(emit func(Event) error) error {
+			time.Sleep(50 * time.Millisecond)
+			panic("boom")
+		}, WithSSEHeartbeat(10*time.Millisecond))
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, ": ping\n\n") {
+		t.Errorf("missing heartbeat, body = %q", body)
+	}
+	// panic 恢复后只推送 error 事件，不再追加统一 JSON 响应
+	if !strings.HasSuffix(body, "event: error\ndata: {\"code\":1000,\"message\":\"server error\",\"data\":null}\n\n") {
+		t.Errorf("body = %q, want trailing error event", body)
+	}
+}
+
+func TestContext_SSE_ClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+
+	e := New(WithMode("test"))
+	e.GET("/events", func(c *Context) {
+		result <- c.SSE(c, func(emit func(Event) error) error {
+			for i := 0; ; i++ {
+				if i == 1 {
+					cancel()
+				}
+				if err := emit(Event{Data: i}); err != nil {
+					return err
+				}
+			}
+		})
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+
+	if err := <-result; err != context.Canceled {
+		t.Errorf("SSE error = %v, want context.Canceled", err)
+	}
+	if body := w.Body.String(); body != "data: 0\n\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestContext_Stream_WriteTimeout(t *testing.T) {
+	e := New(WithMode("test"))
+	e.GET("/rows", func(c *Context) {
+		_ = c.Stream(c, "application/x-ndjson", func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			for i := range 4 {
+				time.Sleep(40 * time.Millisecond)
+				if err := enc.Encode(map[string]int{"i": i}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	// 总耗时超过 WriteTimeout，每次写入顺延写超时后仍能完整输出
+	srv := httptest.NewUnstartedServer(e)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v, got %q", err, data)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if n := strings.Count(string(data), "\n"); n != 4 {
+		t.Errorf("body = %q, want 4 rows", data)
+	}
+}
+
+func TestRouteBuilder_SSE(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{}), WithEncoders(MsgPackEncoder()))
+	handler := func(c *Context) {}
+	e.API().GET("/events", handler).SSE(userResp{}).Errors(ErrNotFound).Done()
+	e.API().GET("/rows", handler).Stream("application/x-ndjson", nil).Done()
+
+	doc := buildTestDoc(t, e)
+
+	content := doc.Paths["/events"].Get.Responses["200"].Content
+	media := content[MIMEEventStream]
+	if len(content) != 1 || media == nil || media.Schema == nil || !strings.Contains(media.Schema.Ref, "userResp") {
+		t.Errorf("/events content = %#v", content)
+	}
+	if content := doc.Paths["/events"].Get.Responses["404"].Content; content["application/json"] == nil {
+		t.Errorf("/events 404 content = %#v, want envelope", content)
+	}
+	content = doc.Paths["/rows"].Get.Responses["200"].Content
+	if media := content["application/x-ndjson"]; len(content) != 1 || media == nil || media.Schema == nil || media.Schema.Type != "string" {
+		t.Errorf("/rows content = %#v", content)
+	}
+}
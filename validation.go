@@ -0,0 +1,301 @@
+package qi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/tokmz/qi/internal/openapi/validate"
+	qiregexp "github.com/tokmz/qi/utils/regexp"
+)
+
+// FieldError 字段级校验错误，透传自 internal/openapi/validate.FieldError。
+type FieldError = validate.FieldError
+
+// ValidationErrors 字段级校验错误列表。
+// 作为原始错误附加到 *errors.Error 时（如 ErrInvalidParams.WithErr(errs)），
+// Context.Fail 会将其输出到响应的 data.errors。
+type ValidationErrors []FieldError
+
+// Error 实现 error 接口。
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, e := range v {
+		parts[i] = e.Field + ": " + e.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// fieldErrors 从错误链中提取字段级校验错误。
+func fieldErrors(err error) (ValidationErrors, bool) {
+	var v ValidationErrors
+	if errors.As(err, &v) && len(v) > 0 {
+		return v, true
+	}
+	return nil, false
+}
+
+// OpenAPIValidator 返回按 OpenAPI 文档校验请求的中间件。
+// 路径参数、查询参数、请求头、Cookie 和请求体不符合文档时，以 ErrInvalidParams 响应，
+// data.errors 为字段级错误列表；文档中没有的路由直接放行。
+// 文档在首个请求时构建，因此中间件可以在注册路由之前挂载。未启用 OpenAPI 时 panic。
+//
+// 示例：
+//
+//	e.Use(e.OpenAPIValidator())
+//	admin := e.Group("/admin", e.OpenAPIValidator())
+func (e *Engine) OpenAPIValidator() HandlerFunc {
+	if e.api == nil {
+		panic("qi: OpenAPIValidator requires WithOpenAPI")
+	}
+	var (
+		once      sync.Once
+		validator *validate.Validator
+	)
+	return func(c *Context) {
+		once.Do(func() {
+			doc, err := e.api.Build()
+			if err != nil {
+				log.Printf("qi: OpenAPI validator disabled, spec build failed: %v", err)
+				return
+			}
+			validator = validate.New(doc)
+		})
+		if validator == nil || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		params := make(map[string]string, len(c.ctx.Params))
+		for _, p := range c.ctx.Params {
+			params[p.Key] = p.Value
+		}
+		errs, ok := validator.Request(c.Request(), ginPathToOpenAPI(c.FullPath()), params)
+		if ok && len(errs) > 0 {
+			fields := ValidationErrors(errs)
+			c.localizeFieldErrors(fields)
+			c.Fail(ErrInvalidParams.WithErr(fields))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ===== 自定义校验规则 =====
+
+// builtinValidations qi 内置的校验规则，基于 utils/regexp，OpenAPI 文档中输出为对应的 pattern。
+var builtinValidations = map[string]func(string) bool{
+	"phone":      qiregexp.ValidatePhone,      // 中国大陆手机号
+	"idcard":     qiregexp.ValidateIDCard,     // 中国居民身份证号
+	"creditcode": qiregexp.ValidateCreditCode, // 统一社会信用代码
+	"bankcard":   qiregexp.ValidateBankCard,   // 银行卡号
+}
+
+var builtinValidationsOnce sync.Once
+
+// RegisterValidation 注册自定义校验规则，注册后可在 binding tag 中使用，如 `binding:"required,sku"`。
+// 规则注册在 gin 的全局校验器上，对进程内所有 Engine 生效，应在处理请求之前完成注册。
+// 内置规则 phone、idcard、creditcode、bankcard 在 New 时自动注册；
+// 自定义规则的错误消息可通过 I18nConfig.Rules 配置。
+//
+// 示例：
+//
+//	qi.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+//		return strings.HasPrefix(fl.Field().String(), "SKU-")
+//	})
+func RegisterValidation(tag string, fn validator.Func) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("qi: binding validator %T does not support custom rules", binding.Validator.Engine())
+	}
+	return v.RegisterValidation(tag, fn)
+}
+
+// registerBuiltinValidations 注册内置校验规则，只执行一次。
+func registerBuiltinValidations() {
+	builtinValidationsOnce.Do(func() {
+		for tag, fn := range builtinValidations {
+			if err := RegisterValidation(tag, stringRule(fn)); err != nil {
+				log.Printf("qi: register validation %q failed: %v", tag, err)
+			}
+		}
+	})
+}
+
+// stringRule 将字符串校验函数包装为 validator.Func，非字符串字段视为不通过。
+func stringRule(fn func(string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		f := fl.Field()
+		return f.Kind() == reflect.String && fn(f.String())
+	}
+}
+
+// ===== 绑定错误转换 =====
+
+// bindLocations 各绑定来源查找字段名时使用的 tag，按优先级排列。
+var bindLocations = map[string][]string{
+	"body":   {"json", "form", "xml"},
+	"query":  {"form", "json"},
+	"path":   {"uri", "json"},
+	"header": {"header", "json"},
+	"cookie": {"cookie", "json"},
+}
+
+// bindFieldErrors 将绑定错误转换为字段级错误。字段名按 json/form/uri/header/cookie tag 取值，
+// in 为默认来源（body/query/path/header/cookie），带 uri、header、cookie tag 的顶层字段归为对应来源。
+// 无法识别的错误返回 false。
+func (c *Context) bindFieldErrors(obj any, in string, err error) (ValidationErrors, bool) {
+	var (
+		verrs     validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		numErr    *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &verrs):
+		root := reflect.TypeOf(obj)
+		// 请求体非表单时，仅有 form tag 的字段来自查询参数
+		formInQuery := in != "body" || !isFormContentType(c.ContentType())
+		out := make(ValidationErrors, 0, len(verrs))
+		for _, fe := range verrs {
+			field, loc := bindFieldPath(root, fe.StructNamespace(), in, formInQuery)
+			out = append(out, FieldError{
+				Field:   field,
+				In:      loc,
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: c.ruleMessage(fe.Tag(), ruleUnit(fe.Kind()), fe.Param()),
+			})
+		}
+		return out, len(out) > 0
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		typ := jsonTypeName(typeErr.Type.Kind())
+		return ValidationErrors{{Field: field, In: "body", Rule: "type", Param: typ, Message: c.ruleMessage("type", "", typ)}}, true
+	case errors.As(err, &syntaxErr):
+		return ValidationErrors{{Field: "body", In: "body", Rule: "format", Param: "json", Message: c.ruleMessage("format", "", "json")}}, true
+	case errors.As(err, &numErr):
+		// 表单/查询参数的数值转换失败不含字段名
+		return ValidationErrors{{Field: in, In: in, Rule: "type", Param: "number", Message: c.ruleMessage("type", "", "number")}}, true
+	}
+	return nil, false
+}
+
+// bindFieldPath 将 validator 的结构体命名空间（如 "CreateReq.Items[0].Name"）转换为 tag 名路径（如 "items[0].name"）。
+// formInQuery 为 true 时仅有 form tag 的顶层字段归为 query。
+func bindFieldPath(root reflect.Type, ns, in string, formInQuery bool) (string, string) {
+	segments := strings.Split(ns, ".")
+	if len(segments) > 1 {
+		segments = segments[1:] // 去掉根类型名
+	}
+	t := root
+	loc := in
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		name, index := seg, ""
+		if i := strings.IndexByte(seg, '['); i >= 0 {
+			name, index = seg[:i], seg[i:]
+		}
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		var sf reflect.StructField
+		ok := false
+		if t != nil && t.Kind() == reflect.Struct {
+			sf, ok = t.FieldByName(name)
+		}
+		if !ok {
+			parts = append(parts, seg)
+			t = nil
+			continue
+		}
+		t = sf.Type
+		if index != "" {
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if k := t.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+				t = t.Elem()
+			}
+		}
+		if len(parts) == 0 {
+			loc = fieldLocation(sf, loc, formInQuery)
+		}
+		tagName := fieldTagName(sf, bindLocations[loc])
+		if sf.Anonymous && tagName == "" {
+			continue // 嵌入结构体的字段在 JSON 中展开
+		}
+		if tagName == "" {
+			tagName = sf.Name
+		}
+		parts = append(parts, tagName+index)
+	}
+	return strings.Join(parts, "."), loc
+}
+
+// fieldLocation 按顶层字段的 tag 返回绑定来源：uri 为 path，header、cookie 为同名来源，
+// formInQuery 时仅有 form tag 的字段为 query，否则为 in。
+func fieldLocation(sf reflect.StructField, in string, formInQuery bool) string {
+	switch {
+	case sf.Tag.Get("uri") != "":
+		return "path"
+	case sf.Tag.Get("header") != "":
+		return "header"
+	case sf.Tag.Get("cookie") != "":
+		return "cookie"
+	case formInQuery && sf.Tag.Get("form") != "" && sf.Tag.Get("json") == "":
+		return "query"
+	}
+	return in
+}
+
+// fieldTagName 按顺序返回第一个有效的 tag 名。
+func fieldTagName(sf reflect.StructField, tags []string) string {
+	for _, tag := range tags {
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ruleUnit 返回规则消息的单位后缀：字符串为 "length"，集合为 "items"，数值为空。
+func ruleUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+	return ""
+}
+
+// jsonTypeName 返回 Go 类型对应的 JSON Schema 类型名。
+func jsonTypeName(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,203 @@
+package qi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type validatedQuery struct {
+	Page   int    `form:"page" binding:"min=1"`
+	Status string `form:"status" binding:"omitempty,oneof=active disabled"`
+}
+
+type validatedHeader struct {
+	Tenant string `header:"X-Tenant-Id" binding:"required"`
+}
+
+type validatedBody struct {
+	Name  string   `json:"name" binding:"required,min=2"`
+	Email string   `json:"email" binding:"omitempty,email"`
+	Tags  []string `json:"tags" binding:"max=2"`
+}
+
+func TestOpenAPIValidator(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{Title: "validate"}))
+	e.Use(e.OpenAPIValidator())
+	handler := func(c *Context) { c.OK(nil) }
+	e.API().GET("/users", handler).Query(validatedQuery{}).Headers(validatedHeader{}).Done()
+	e.API().POST("/users", handler).Body(validatedBody{}).Done()
+	e.API().POST("/bound", Bind(func(c *Context, req *createUserReq) (*userResp, error) {
+		return &userResp{Name: req.Name}, nil
+	})).Done()
+	e.GET("/raw", handler)
+
+	do := func(req *http.Request) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		m, err := parseResponse(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("invalid json: %v, body = %s", err, w.Body.String())
+		}
+		return w.Code, m
+	}
+	fields := func(m map[string]any) map[string]string {
+		out := make(map[string]string)
+		data, _ := m["data"].(map[string]any)
+		list, _ := data["errors"].([]any)
+		for _, item := range list {
+			fe := item.(map[string]any)
+			out[fe["field"].(string)] = fe["rule"].(string)
+		}
+		return out
+	}
+
+	req := httptest.NewRequest("GET", "/users?page=0&status=deleted", nil)
+	code, m := do(req)
+	if code != http.StatusBadRequest || m["code"] != float64(ErrInvalidParams.Code) || m["message"] != ErrInvalidParams.Message {
+		t.Fatalf("status = %d, body = %v", code, m)
+	}
+	want := map[string]string{"page": "minimum", "status": "enum", "X-Tenant-Id": "required"}
+	if got := fields(m); len(got) != len(want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	} else {
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("errors[%s] = %q, want %q", k, got[k], v)
+			}
+		}
+	}
+
+	req = httptest.NewRequest("GET", "/users?page=2", nil)
+	req.Header.Set("X-Tenant-Id", "t1")
+	if code, m := do(req); code != http.StatusOK {
+		t.Errorf("valid query: status = %d, body = %v", code, m)
+	}
+
+	req = httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"a","email":"bad","tags":["x","y","z"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	code, m = do(req)
+	got := fields(m)
+	if code != http.StatusBadRequest || got["name"] != "minLength" || got["email"] != "format" || got["tags"] != "maxItems" {
+		t.Errorf("invalid body: status = %d, errors = %v", code, got)
+	}
+
+	req = httptest.NewRequest("POST", "/users", strings.NewReader(`{"email":"a@b.co"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, m := do(req); fields(m)["name"] != "required" {
+		t.Errorf("missing name: errors = %v", fields(m))
+	}
+
+	// 校验读取的请求体需还原，handler 仍可绑定
+	req = httptest.NewRequest("POST", "/bound", strings.NewReader(`{"name":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if code, m := do(req); code != http.StatusOK || m["data"].(map[string]any)["name"] != "bob" {
+		t.Errorf("bound route: status = %d, body = %v", code, m)
+	}
+
+	if code, _ := do(httptest.NewRequest("GET", "/raw?page=0", nil)); code != http.StatusOK {
+		t.Errorf("undocumented route status = %d, want 200", code)
+	}
+}
+
+func TestContext_FailValidationErrors(t *testing.T) {
+	c, w := newTestContext()
+	c.Fail(ErrInvalidParams.WithErr(ValidationErrors{{Field: "name", In: "body", Rule: "required", Message: "is required"}}))
+
+	m, _ := parseResponse(w.Body.Bytes())
+	if m["message"] != ErrInvalidParams.Message {
+		t.Errorf("message = %v, want %q", m["message"], ErrInvalidParams.Message)
+	}
+	data, _ := m["data"].(map[string]any)
+	if list, _ := data["errors"].([]any); len(list) != 1 {
+		t.Errorf("data = %v, want one field error", m["data"])
+	}
+}
+
+type companyReq struct {
+	Phone      string `json:"phone" binding:"required,phone"`
+	IDCard     string `json:"id_card" binding:"omitempty,idcard"`
+	CreditCode string `json:"credit_code" binding:"omitempty,creditcode"`
+	BankCard   string `json:"bank_card" binding:"omitempty,bankcard"`
+	SKU        string `json:"sku" binding:"omitempty,qi_sku"`
+}
+
+func TestRegisterValidation(t *testing.T) {
+	e := New(WithMode("test"), WithOpenAPI(&OpenAPIConfig{Title: "rules"}))
+	if err := RegisterValidation("qi_sku", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU-")
+	}); err != nil {
+		t.Fatalf("RegisterValidation: %v", err)
+	}
+	e.API().POST("/companies", Bind(func(c *Context, req *companyReq) (*companyReq, error) {
+		return req, nil
+	})).Done()
+
+	messages := make(map[string]string)
+	rules := func(body string) map[string]string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/companies", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		e.ServeHTTP(w, req)
+		m, err := parseResponse(w.Body.Bytes())
+		if err != nil {
+			t.Fatalf("invalid json: %v, body = %s", err, w.Body.String())
+		}
+		out := make(map[string]string)
+		data, _ := m["data"].(map[string]any)
+		list, _ := data["errors"].([]any)
+		for _, item := range list {
+			fe := item.(map[string]any)
+			out[fe["field"].(string)] = fe["rule"].(string)
+			messages[fe["field"].(string)] = fe["message"].(string)
+		}
+		return out
+	}
+
+	got := rules(`{"phone":"13812345678","id_card":"110101199003074578","credit_code":"91350100M000100Y43","bank_card":"6222021234567890123","sku":"SKU-1"}`)
+	if len(got) != 0 {
+		t.Errorf("valid request got field errors %v", got)
+	}
+
+	got = rules(`{"phone":"12345","id_card":"123","credit_code":"abc","bank_card":"12ab","sku":"X-1"}`)
+	want := map[string]string{"phone": "phone", "id_card": "idcard", "credit_code": "creditcode", "bank_card": "bankcard", "sku": "qi_sku"}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("field %s rule = %q, want %q (all: %v)", field, got[field], rule, got)
+		}
+	}
+	// 未启用国际化时使用内置英文消息
+	if msg := messages["phone"]; msg != "must be a valid mobile phone number" {
+		t.Errorf("phone message = %q", msg)
+	}
+	if msg := messages["credit_code"]; msg != "must be a valid unified social credit code" {
+		t.Errorf("credit_code message = %q", msg)
+	}
+
+	doc, err := e.api.Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	found := false
+	for name, s := range doc.Components.Schemas {
+		if !strings.HasSuffix(name, "companyReq.Body") {
+			continue
+		}
+		found = true
+		for _, field := range []string{"phone", "id_card", "credit_code", "bank_card"} {
+			prop := s.Properties[field]
+			if prop == nil || prop.Pattern == "" || len(prop.XConstraints) > 0 {
+				t.Errorf("%s: want pattern without x-constraints, got %#v", field, prop)
+			}
+		}
+		if _, ok := s.Properties["sku"].XConstraints["qi_sku"]; !ok {
+			t.Errorf("sku: want x-constraints qi_sku, got %#v", s.Properties["sku"])
+		}
+	}
+	if !found {
+		t.Fatalf("companyReq body schema not found")
+	}
+}
@@ -0,0 +1,299 @@
+package qi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WorkerState 后台任务状态。
+type WorkerState string
+
+// 后台任务状态。
+const (
+	WorkerPending WorkerState = "pending" // 等待 Run 启动
+	WorkerRunning WorkerState = "running" // 运行中
+	WorkerBackoff WorkerState = "backoff" // 出错后等待重启
+	WorkerStopped WorkerState = "stopped" // 正常返回或随关闭退出
+	WorkerFailed  WorkerState = "failed"  // 出错且不再重启
+)
+
+// WorkerStatus 后台任务状态快照。
+type WorkerStatus struct {
+	Name      string      `json:"name"`
+	State     WorkerState `json:"state"`
+	Restarts  int         `json:"restarts"`             // 已重启次数
+	LastError string      `json:"last_error,omitempty"` // 最近一次错误
+	StartedAt time.Time   `json:"started_at,omitzero"`  // 最近一次启动时间
+}
+
+// RestartPolicy 后台任务出错（返回错误或 panic）后的重启策略，正常返回 nil 时不重启。
+// 重启间隔从 InitialBackoff 开始逐次翻倍，不超过 MaxBackoff；
+// 单次运行时间超过 MaxBackoff 时视为已恢复，重启间隔重新计算。
+type RestartPolicy struct {
+	MaxRestarts    int           // 最大连续重启次数，0 表示不限制
+	InitialBackoff time.Duration // 首次重启间隔，默认 1s
+	MaxBackoff     time.Duration // 最大重启间隔，默认 30s
+}
+
+func (p *RestartPolicy) normalize() {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+}
+
+// WorkerOption 后台任务选项。
+type WorkerOption func(*worker)
+
+// WithRestart 出错后按策略重启后台任务，policy 为 nil 时使用默认策略。
+func WithRestart(policy *RestartPolicy) WorkerOption {
+	return func(w *worker) {
+		if policy == nil {
+			policy = &RestartPolicy{}
+		}
+		p := *policy
+		p.normalize()
+		w.restart = &p
+	}
+}
+
+// Go 注册由 Engine 托管的后台任务（消息队列消费者、定时任务等），任务名为空或重复时 panic。
+// Run 在 HTTP 服务开始监听之后启动任务，收到关闭信号时取消 ctx，
+// 并在关闭组件之前等待任务退出（受 ShutdownTimeout 限制）。
+// Run 启动后调用 Go 时任务立即启动。任务状态通过 Workers 查询。
+//
+// 示例：
+//
+//	e.Go("orders-consumer", func(ctx context.Context) error {
+//		return consumer.Subscribe(ctx, "orders", handleOrder)
+//	}, qi.WithRestart(&qi.RestartPolicy{MaxBackoff: time.Minute}))
+func (e *Engine) Go(name string, fn func(ctx context.Context) error, opts ...WorkerOption) {
+	if name == "" {
+		panic("qi: worker name is required")
+	}
+	w := &worker{name: name, fn: fn, status: WorkerStatus{Name: name, State: WorkerPending}}
+	for _, opt := range opts {
+		opt(w)
+	}
+	e.workers.add(w)
+}
+
+// Workers 返回所有后台任务的状态，按任务名排序。
+func (e *Engine) Workers() []WorkerStatus {
+	return e.workers.statuses()
+}
+
+// worker 单个后台任务。
+type worker struct {
+	name    string
+	fn      func(ctx context.Context) error
+	restart *RestartPolicy
+
+	mu     sync.Mutex
+	status WorkerStatus
+	alive  bool // 已启动且 supervise 尚未返回，包括重启等待中的任务
+}
+
+// workerGroup 后台任务监督器。
+type workerGroup struct {
+	mu      sync.Mutex
+	workers []*worker
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+func (g *workerGroup) add(w *worker) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, exist := range g.workers {
+		if exist.name == w.name {
+			panic("qi: duplicate worker " + w.name)
+		}
+	}
+	if g.stopped {
+		w.setState(WorkerStopped, nil)
+	}
+	g.workers = append(g.workers, w)
+	if g.ctx != nil && !g.stopped {
+		g.launch(w)
+	}
+}
+
+// start 启动所有已注册的任务。
+func (g *workerGroup) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ctx != nil || g.stopped {
+		return
+	}
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	for _, w := range g.workers {
+		g.launch(w)
+	}
+}
+
+// launch 在新的 goroutine 中运行任务，调用方需持有 g.mu。
+func (g *workerGroup) launch(w *worker) {
+	w.mu.Lock()
+	w.alive = true
+	w.mu.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		w.supervise(g.ctx)
+		w.mu.Lock()
+		w.alive = false
+		w.mu.Unlock()
+	}()
+}
+
+// cancelAll 取消所有任务的 ctx，之后注册的任务不再启动。
+func (g *workerGroup) cancelAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = true
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// stop 取消所有任务的 ctx 并等待退出；ctx 结束时返回仍未退出的任务。
+func (g *workerGroup) stop(ctx context.Context) error {
+	g.cancelAll()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	// ctx 与 done 同时就绪时 select 随机选择，任务已全部退出时不返回错误
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+	// alive 在 wg.Done 之前清除，列表为空说明任务已在此期间全部退出
+	alive := g.alive()
+	if len(alive) == 0 {
+		return nil
+	}
+	return fmt.Errorf("qi: stop workers %v: %w", alive, ctx.Err())
+}
+
+// alive 返回已启动但尚未退出的任务名，按任务名排序。
+func (g *workerGroup) alive() []string {
+	g.mu.Lock()
+	workers := append([]*worker(nil), g.workers...)
+	g.mu.Unlock()
+
+	var names []string
+	for _, w := range workers {
+		w.mu.Lock()
+		if w.alive {
+			names = append(names, w.name)
+		}
+		w.mu.Unlock()
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *workerGroup) statuses() []WorkerStatus {
+	g.mu.Lock()
+	workers := append([]*worker(nil), g.workers...)
+	g.mu.Unlock()
+
+	out := make([]WorkerStatus, 0, len(workers))
+	for _, w := range workers {
+		w.mu.Lock()
+		out = append(out, w.status)
+		w.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// supervise 运行任务直到正常返回、ctx 取消或不再重启。
+func (w *worker) supervise(ctx context.Context) {
+	restarts := 0
+	backoff := time.Duration(0)
+	for {
+		begin := time.Now()
+		w.mu.Lock()
+		w.status.State = WorkerRunning
+		w.status.StartedAt = begin
+		w.mu.Unlock()
+
+		err := w.run(ctx)
+		if ctx.Err() != nil || err == nil {
+			w.setState(WorkerStopped, err)
+			return
+		}
+		if w.restart == nil {
+			log.Printf("qi: worker %s failed: %v", w.name, err)
+			w.setState(WorkerFailed, err)
+			return
+		}
+
+		if time.Since(begin) > w.restart.MaxBackoff {
+			restarts, backoff = 0, 0
+		}
+		if w.restart.MaxRestarts > 0 && restarts >= w.restart.MaxRestarts {
+			log.Printf("qi: worker %s failed after %d restarts: %v", w.name, restarts, err)
+			w.setState(WorkerFailed, err)
+			return
+		}
+		backoff = min(max(backoff*2, w.restart.InitialBackoff), w.restart.MaxBackoff)
+		restarts++
+		log.Printf("qi: worker %s failed: %v, restarting in %v", w.name, err, backoff)
+		w.setState(WorkerBackoff, err)
+		w.mu.Lock()
+		w.status.Restarts++
+		w.mu.Unlock()
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			w.setState(WorkerStopped, nil)
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// run 执行一次任务，panic 转换为错误。
+func (w *worker) run(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.fn(ctx)
+}
+
+// setState 更新状态，err 为 nil 或 context.Canceled 时保留上一次错误。
+func (w *worker) setState(state WorkerState, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.State = state
+	if err != nil && !errors.Is(err, context.Canceled) {
+		w.status.LastError = err.Error()
+	}
+}
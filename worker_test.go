@@ -0,0 +1,134 @@
+package qi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEngine_Go(t *testing.T) {
+	e := New(WithMode("test"))
+	var attempts atomic.Int32
+	e.Go("flaky", func(ctx context.Context) error {
+		if attempts.Add(1) < 3 {
+			panic("broker unreachable")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithRestart(&RestartPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}))
+	e.Go("once", func(context.Context) error { return errors.New("bad config") })
+	e.Go("limited", func(context.Context) error {
+		return errors.New("boom")
+	}, WithRestart(&RestartPolicy{MaxRestarts: 2, InitialBackoff: time.Millisecond}))
+
+	if s := e.Workers(); len(s) != 3 || s[0].State != WorkerPending {
+		t.Fatalf("workers before start = %+v", s)
+	}
+	e.workers.start()
+
+	status := func(name string) WorkerStatus {
+		for _, s := range e.Workers() {
+			if s.Name == name {
+				return s
+			}
+		}
+		t.Fatalf("worker %s not found", name)
+		return WorkerStatus{}
+	}
+	waitFor(t, func() bool {
+		return status("flaky").State == WorkerRunning && attempts.Load() == 3 &&
+			status("once").State == WorkerFailed && status("limited").State == WorkerFailed
+	})
+	if s := status("flaky"); s.Restarts != 2 || s.LastError != "panic: broker unreachable" {
+		t.Errorf("flaky = %+v", s)
+	}
+	if s := status("once"); s.Restarts != 0 || s.LastError != "bad config" {
+		t.Errorf("once = %+v", s)
+	}
+	if s := status("limited"); s.Restarts != 2 || s.LastError != "boom" {
+		t.Errorf("limited = %+v", s)
+	}
+
+	// Run 启动后注册的任务立即启动
+	started := make(chan struct{})
+	e.Go("late", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+	<-started
+
+	if err := e.shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"flaky", "late"} {
+		if s := status(name); s.State != WorkerStopped {
+			t.Errorf("%s after shutdown = %+v", name, s)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate worker should panic")
+		}
+	}()
+	e.Go("once", func(context.Context) error { return nil })
+}
+
+func TestEngine_GoShutdownOrder(t *testing.T) {
+	e := New(WithMode("test"))
+	var workerDone atomic.Bool
+	var dbStoppedFirst bool
+	e.Register(Component{Name: "db", Stop: func(context.Context) error {
+		dbStoppedFirst = !workerDone.Load()
+		return nil
+	}})
+	e.Go("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		workerDone.Store(true)
+		return nil
+	})
+
+	if err := e.lifecycle.start(context.Background(), e.shutdownContext); err != nil {
+		t.Fatal(err)
+	}
+	e.workers.start()
+	waitFor(t, func() bool { return e.Workers()[0].State == WorkerRunning })
+	if err := e.shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	// 组件关闭时依赖它的后台任务已退出
+	if dbStoppedFirst {
+		t.Error("db stopped before consumer exited")
+	}
+
+	// 忽略 ctx 的任务不会拖住关闭流程
+	e = New(WithMode("test"), func(c *Config) { c.ShutdownTimeout = 20 * time.Millisecond })
+	release := make(chan struct{})
+	defer close(release)
+	e.Go("stuck", func(context.Context) error {
+		<-release
+		return nil
+	})
+	e.workers.start()
+	waitFor(t, func() bool { return e.Workers()[0].State == WorkerRunning })
+	err := e.shutdown()
+	if err == nil || !strings.Contains(err.Error(), "qi: stop workers [stuck]") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown error = %v", err)
+	}
+
+	// ctx 已结束但没有未退出的任务时不返回错误
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 100 {
+		var g workerGroup
+		g.start()
+		if err := g.stop(ctx); err != nil {
+			t.Fatalf("stop without workers = %v", err)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package qi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	qierrors "github.com/tokmz/qi/pkg/errors"
+)
+
+// WebSocket 消息类型。
+const (
+	WSTextMessage   = websocket.TextMessage
+	WSBinaryMessage = websocket.BinaryMessage
+)
+
+// WebSocketConfig WebSocket 配置
+type WebSocketConfig struct {
+	ReadBufferSize    int                        // 读缓冲区大小，0 时使用 HTTP 服务的缓冲区
+	WriteBufferSize   int                        // 写缓冲区大小，0 时使用 HTTP 服务的缓冲区
+	CheckOrigin       func(r *http.Request) bool // 校验 Origin 请求头，nil 时仅允许同源
+	Subprotocols      []string                   // 支持的子协议，按优先级排列
+	EnableCompression bool                       // 启用 permessage-deflate 压缩
+	PingInterval      time.Duration              // ping 间隔，默认 30s
+	PongWait          time.Duration              // 等待消息或 pong 的超时时间，默认 60s
+	WriteTimeout      time.Duration              // 单次写入超时时间，默认 10s
+	MaxMessageSize    int64                      // 单条消息最大字节数，默认 1MB
+}
+
+// WithWebSocket 配置 WebSocket，未配置时使用默认值。
+func WithWebSocket(cfg *WebSocketConfig) Option {
+	return func(c *Config) {
+		c.wsConfig = cfg
+	}
+}
+
+func (c *WebSocketConfig) normalize() {
+	if c.PingInterval <= 0 {
+		c.PingInterval = 30 * time.Second
+	}
+	if c.PongWait <= 0 {
+		c.PongWait = 60 * time.Second
+	}
+	if c.PongWait <= c.PingInterval {
+		c.PongWait = c.PingInterval * 2
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 1 << 20
+	}
+}
+
+// WSHandler WebSocket 处理函数，返回后连接关闭：返回 nil 或对端已关闭时正常关闭（1000），
+// 其他错误以 1011 关闭，错误消息作为关闭原因。
+type WSHandler func(conn *WSConn) error
+
+// WS 注册 WebSocket 路由（GET），握手请求经过完整的中间件链（鉴权、链路追踪等）。
+//
+// 示例：
+//
+//	e.WS("/ws", func(conn *qi.WSConn) error {
+//		for {
+//			var msg Message
+//			if err := conn.ReadJSON(&msg); err != nil {
+//				return err
+//			}
+//			if err := conn.WriteJSON(msg); err != nil {
+//				return err
+//			}
+//		}
+//	})
+func (e *Engine) WS(path string, handler WSHandler, middlewares ...HandlerFunc) {
+	normalized := normalizeAbsolutePath(path)
+	e.handleWS(normalized, normalized, middlewares, handler)
+}
+
+// WS 在当前分组下注册 WebSocket 路由，握手请求经过分组中间件。
+func (r *RouterGroup) WS(path string, handler WSHandler, middlewares ...HandlerFunc) {
+	chain := append(cloneHandlers(r.middlewares), middlewares...)
+	r.engine.handleWS(normalizeAbsolutePath(path), joinPaths(r.prefix, path), chain, handler)
+}
+
+// Hub 返回 Engine 的 WebSocket 连接中心，所有 WebSocket 连接自动注册，用于广播和房间管理。
+func (e *Engine) Hub() *Hub {
+	return e.hub
+}
+
+// handleWS 注册 WebSocket 路由，路由表中的 handler 名称取自 WSHandler。
+func (e *Engine) handleWS(relativePath, fullPath string, middlewares HandlersChain, handler WSHandler) {
+	e.handle(http.MethodGet, relativePath, fullPath, middlewares, e.upgrade(handler))
+
+	name := cleanHandlerName(runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name())
+	route := e.router.routes[len(e.router.routes)-1]
+	route.HandlerName = name
+	e.routeMeta[http.MethodGet+":"+route.FullPath] = RouteMeta{Summary: name}
+}
+
+// upgrade 返回完成握手并运行 WSHandler 的 HandlerFunc，握手失败时以统一错误响应。
+func (e *Engine) upgrade(handler WSHandler) HandlerFunc {
+	// 复制一份再填充默认值，不修改调用方传入的配置
+	var cfg WebSocketConfig
+	if e.cfg.wsConfig != nil {
+		cfg = *e.cfg.wsConfig
+	}
+	cfg.normalize()
+	return func(c *Context) {
+		upgrader := websocket.Upgrader{
+			ReadBufferSize:    cfg.ReadBufferSize,
+			WriteBufferSize:   cfg.WriteBufferSize,
+			CheckOrigin:       cfg.CheckOrigin,
+			Subprotocols:      cfg.Subprotocols,
+			EnableCompression: cfg.EnableCompression,
+			Error: func(_ http.ResponseWriter, _ *http.Request, status int, reason error) {
+				c.Fail(qierrors.NewWithStatus(ErrBadRequest.Code, status, reason.Error()))
+			},
+		}
+		ws, err := upgrader.Upgrade(c.ctx.Writer, c.ctx.Request, nil)
+		if err != nil {
+			return
+		}
+		conn := newWSConn(c, ws, &cfg, e.hub)
+		if !e.hub.add(conn) {
+			_ = conn.closeWith(websocket.CloseGoingAway, "server shutting down")
+			return
+		}
+		defer e.hub.wg.Done()
+		conn.serve(handler)
+	}
+}
+
+// ===== 连接 =====
+
+// WSConn WebSocket 连接。读方法只能在一个 goroutine 中调用，写方法可并发调用。
+type WSConn struct {
+	id     string
+	conn   *websocket.Conn
+	c      *Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    *WebSocketConfig
+	hub    *Hub
+
+	writeMu   sync.Mutex
+	closeSent sync.Once
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newWSConn(c *Context, ws *websocket.Conn, cfg *WebSocketConfig, hub *Hub) *WSConn {
+	ctx, cancel := context.WithCancel(c.Context())
+	conn := &WSConn{
+		id:     uuid.NewString(),
+		conn:   ws,
+		c:      c,
+		ctx:    ctx,
+		cancel: cancel,
+		cfg:    cfg,
+		hub:    hub,
+	}
+	ws.SetReadLimit(cfg.MaxMessageSize)
+	_ = ws.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
+	return conn
+}
+
+// ID 返回连接的唯一标识。
+func (w *WSConn) ID() string {
+	return w.id
+}
+
+// Context 返回连接级 context：继承握手请求的 context（含链路追踪 span），连接关闭或服务关闭时取消。
+func (w *WSConn) Context() context.Context {
+	return w.ctx
+}
+
+// Request 返回握手请求。
+func (w *WSConn) Request() *http.Request {
+	return w.c.Request()
+}
+
+// Get 读取握手请求中间件写入的值（如鉴权后的用户信息）。
+func (w *WSConn) Get(key string) (any, bool) {
+	return w.c.Get(key)
+}
+
+// Subprotocol 返回协商的子协议。
+func (w *WSConn) Subprotocol() string {
+	return w.conn.Subprotocol()
+}
+
+// ReadMessage 读取一条消息，返回消息类型（WSTextMessage / WSBinaryMessage）和内容。
+// 连接关闭、读取超时或消息超过 MaxMessageSize 时返回错误，连接级 context 随之取消。
+func (w *WSConn) ReadMessage() (int, []byte, error) {
+	typ, data, err := w.conn.ReadMessage()
+	if err != nil {
+		w.cancel()
+		return 0, nil, err
+	}
+	_ = w.conn.SetReadDeadline(time.Now().Add(w.cfg.PongWait))
+	return typ, data, nil
+}
+
+// ReadJSON 读取一条消息并解码为 JSON。解码失败不影响连接，可继续读取。
+func (w *WSConn) ReadJSON(v any) error {
+	_, data, err := w.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteMessage 发送一条消息。
+func (w *WSConn) WriteMessage(messageType int, data []byte) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	return w.conn.WriteMessage(messageType, data)
+}
+
+// WriteJSON 将 v 编码为 JSON 后以文本消息发送。
+func (w *WSConn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.WriteMessage(websocket.TextMessage, data)
+}
+
+// writePrepared 发送预编码的消息，用于广播。
+func (w *WSConn) writePrepared(pm *websocket.PreparedMessage) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	return w.conn.WritePreparedMessage(pm)
+}
+
+// Join 加入房间。
+func (w *WSConn) Join(room string) {
+	w.hub.Join(w, room)
+}
+
+// Leave 离开房间。
+func (w *WSConn) Leave(room string) {
+	w.hub.Leave(w, room)
+}
+
+// Close 正常关闭连接（1000），可重复调用。
+func (w *WSConn) Close() error {
+	return w.closeWith(websocket.CloseNormalClosure, "")
+}
+
+// closeWith 发送关闭帧后关闭连接，并从 Hub 中移除。
+func (w *WSConn) closeWith(code int, reason string) error {
+	w.sendClose(code, reason)
+	w.closeOnce.Do(func() {
+		w.hub.remove(w)
+		w.closeErr = w.conn.Close()
+	})
+	return w.closeErr
+}
+
+// sendClose 发送关闭帧并取消连接级 context，底层连接保持打开以接收对端的关闭帧。
+func (w *WSConn) sendClose(code int, reason string) {
+	w.closeSent.Do(func() {
+		w.cancel()
+		// 控制帧负载上限 125 字节，关闭码占 2 字节
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		_ = w.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(w.cfg.WriteTimeout))
+	})
+}
+
+// serve 运行 WSHandler 并维持心跳，handler 返回或 panic 后关闭连接。
+func (w *WSConn) serve(handler WSHandler) {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = w.closeWith(websocket.CloseInternalServerErr, "internal error")
+			panic(r)
+		}
+	}()
+	go w.keepalive()
+
+	err := handler(w)
+	var closeErr *websocket.CloseError
+	if err == nil || errors.As(err, &closeErr) || w.ctx.Err() != nil {
+		_ = w.Close()
+		return
+	}
+	_ = w.closeWith(websocket.CloseInternalServerErr, err.Error())
+}
+
+// keepalive 定时发送 ping，发送失败时取消连接级 context。
+func (w *WSConn) keepalive() {
+	ticker := time.NewTicker(w.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.cfg.WriteTimeout)); err != nil {
+				w.cancel()
+				return
+			}
+		}
+	}
+}
+
+// ===== 连接中心 =====
+
+// Hub WebSocket 连接中心，管理 Engine 的所有连接和房间，可并发使用。
+type Hub struct {
+	mu     sync.RWMutex
+	conns  map[*WSConn]map[string]struct{} // 连接 -> 所在房间
+	rooms  map[string]map[*WSConn]struct{} // 房间 -> 连接
+	closed bool
+	wg     sync.WaitGroup // 运行中的 WSHandler
+}
+
+func newHub() *Hub {
+	return &Hub{
+		conns: make(map[*WSConn]map[string]struct{}),
+		rooms: make(map[string]map[*WSConn]struct{}),
+	}
+}
+
+// Count 返回当前连接数。
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount 返回房间内的连接数。
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Join 将连接加入房间，已关闭的连接忽略。
+func (h *Hub) Join(conn *WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rooms, ok := h.conns[conn]
+	if !ok {
+		return
+	}
+	rooms[room] = struct{}{}
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*WSConn]struct{})
+	}
+	h.rooms[room][conn] = struct{}{}
+}
+
+// Leave 将连接移出房间，房间为空时删除。
+func (h *Hub) Leave(conn *WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leave(conn, room)
+}
+
+func (h *Hub) leave(conn *WSConn, room string) {
+	delete(h.conns[conn], room)
+	delete(h.rooms[room], conn)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// Broadcast 向所有连接发送 JSON 消息，发送失败的连接被关闭。
+func (h *Hub) Broadcast(v any) error {
+	h.mu.RLock()
+	targets := make([]*WSConn, 0, len(h.conns))
+	for conn := range h.conns {
+		targets = append(targets, conn)
+	}
+	h.mu.RUnlock()
+	return broadcast(targets, v)
+}
+
+// BroadcastTo 向房间内的连接发送 JSON 消息，发送失败的连接被关闭。
+func (h *Hub) BroadcastTo(room string, v any) error {
+	h.mu.RLock()
+	targets := make([]*WSConn, 0, len(h.rooms[room]))
+	for conn := range h.rooms[room] {
+		targets = append(targets, conn)
+	}
+	h.mu.RUnlock()
+	return broadcast(targets, v)
+}
+
+// broadcast 只编码一次消息，依次发送给各连接。
+func broadcast(targets []*WSConn, v any) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	pm, err := websocket.NewPreparedMessage(websocket.TextMessage, data)
+	if err != nil {
+		return err
+	}
+	for _, conn := range targets {
+		if err := conn.writePrepared(pm); err != nil {
+			_ = conn.closeWith(websocket.CloseGoingAway, "write failed")
+		}
+	}
+	return nil
+}
+
+// add 注册连接，Hub 已关闭时返回 false。
+func (h *Hub) add(conn *WSConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = make(map[string]struct{})
+	h.wg.Add(1)
+	return true
+}
+
+// remove 注销连接并移出所有房间。
+func (h *Hub) remove(conn *WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room := range h.conns[conn] {
+		h.leave(conn, room)
+	}
+	delete(h.conns, conn)
+}
+
+// shutdown 拒绝新连接，向所有连接发送 1001 关闭帧并取消连接级 context，
+// 等待 WSHandler 返回；ctx 结束时强制关闭剩余连接。
+func (h *Hub) shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*WSConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.sendClose(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, conn := range conns {
+			_ = conn.closeWith(websocket.CloseGoingAway, "server shutting down")
+		}
+		return ctx.Err()
+	}
+}
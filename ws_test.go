@@ -0,0 +1,181 @@
+package qi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialWS 连接测试服务的 WebSocket 路由。
+func dialWS(t *testing.T, srv *httptest.Server, path string, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+path, header)
+	if err != nil {
+		t.Fatalf("dial %s: %v (resp %v)", path, err, resp)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitFor 等待条件成立，超时后失败。
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRouterGroup_WS(t *testing.T) {
+	e := New(WithMode("test"))
+	auth := func(c *Context) {
+		token := c.GetHeader("Authorization")
+		if token == "" {
+			c.Fail(ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Set("user", token)
+		c.Next()
+	}
+	api := e.Group("/api", auth)
+	api.WS("/echo", func(conn *WSConn) error {
+		user, _ := conn.Get("user")
+		for {
+			var msg map[string]string
+			if err := conn.ReadJSON(&msg); err != nil {
+				return err
+			}
+			msg["user"] = user.(string)
+			if err := conn.WriteJSON(msg); err != nil {
+				return err
+			}
+		}
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	if routes := e.Routes(); routes[0].HandlerName != "qi.TestRouterGroup_WS.func2" {
+		t.Errorf("route handler = %q", routes[0].HandlerName)
+	}
+
+	// 分组中间件拒绝握手
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/echo", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unauthorized dial: err = %v, resp = %v", err, resp)
+	}
+
+	// 非 WebSocket 请求以统一错误响应
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/echo", nil)
+	req.Header.Set("Authorization", "alice")
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":1001`) {
+		t.Errorf("plain GET: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	conn := dialWS(t, srv, "/api/echo", http.Header{"Authorization": {"alice"}})
+	if err := conn.WriteJSON(map[string]string{"text": "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "hi" || got["user"] != "alice" {
+		t.Errorf("echo = %v", got)
+	}
+}
+
+func TestHub_BroadcastAndShutdown(t *testing.T) {
+	cfg := &WebSocketConfig{PingInterval: 20 * time.Millisecond}
+	e := New(WithMode("test"), WithWebSocket(cfg))
+	returned := make(chan string, 2)
+	e.WS("/ws", func(conn *WSConn) error {
+		if room := conn.Request().URL.Query().Get("room"); room != "" {
+			conn.Join(room)
+		}
+		defer func() { returned <- conn.ID() }()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return err
+			}
+		}
+	})
+	// 默认值填充在副本上，调用方的配置保持不变
+	if cfg.PongWait != 0 || cfg.WriteTimeout != 0 {
+		t.Errorf("WebSocketConfig modified: %+v", cfg)
+	}
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	a := dialWS(t, srv, "/ws?room=a", nil)
+	b := dialWS(t, srv, "/ws", nil)
+	hub := e.Hub()
+	waitFor(t, func() bool { return hub.Count() == 2 && hub.RoomCount("a") == 1 })
+
+	if err := hub.BroadcastTo("a", map[string]int{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Broadcast(map[string]int{"n": 2}); err != nil {
+		t.Fatal(err)
+	}
+	read := func(conn *websocket.Conn) int {
+		var msg map[string]int
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return msg["n"]
+	}
+	if n := read(a); n != 1 {
+		t.Errorf("room a first message n = %d, want 1", n)
+	}
+	if n := read(a); n != 2 {
+		t.Errorf("room a second message n = %d, want 2", n)
+	}
+	if n := read(b); n != 2 {
+		t.Errorf("b first message n = %d, want 2 (not in room a)", n)
+	}
+
+	// 心跳间隔远小于测试耗时，ping/pong 不会中断连接
+	time.Sleep(60 * time.Millisecond)
+
+	// 客户端收到关闭帧后回复，服务端 handler 的读取随之返回
+	closed := make(chan error, 2)
+	for _, conn := range []*websocket.Conn{a, b} {
+		go func() {
+			_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, _, err := conn.ReadMessage()
+			closed <- err
+		}()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := hub.shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	for range 2 {
+		if err := <-closed; !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("read after shutdown: %v, want going away", err)
+		}
+	}
+	if len(returned) != 2 || hub.Count() != 0 || hub.RoomCount("a") != 0 {
+		t.Errorf("handlers returned = %d, conns = %d", len(returned), hub.Count())
+	}
+
+	// 关闭后拒绝新连接
+	c := dialWS(t, srv, "/ws", nil)
+	_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := c.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("dial after shutdown: %v, want going away", err)
+	}
+}